@@ -1,7 +1,16 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
 	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/fire9900/golang-forum/internal/auth"
 	"github.com/fire9900/golang-forum/internal/config"
@@ -9,10 +18,14 @@ import (
 	"github.com/fire9900/golang-forum/internal/middleware"
 	"github.com/fire9900/golang-forum/internal/repository"
 	"github.com/fire9900/golang-forum/internal/service"
+	"github.com/fire9900/golang-forum/internal/storage"
 
 	"github.com/gin-gonic/gin"
 )
 
+// shutdownTimeout ограничивает время завершения обработки текущих запросов
+const shutdownTimeout = 10 * time.Second
+
 // App представляет собой структуру приложения
 type App struct {
 	cfg        *config.Config
@@ -27,9 +40,15 @@ func New(cfg *config.Config) (*App, error) {
 		return nil, err
 	}
 
+	router := gin.Default()
+	// Без явного списка gin доверяет X-Forwarded-For от любого клиента
+	if err := router.SetTrustedProxies(cfg.HTTP.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+
 	return &App{
 		cfg:        cfg,
-		router:     gin.Default(),
+		router:     router,
 		authClient: authClient,
 	}, nil
 }
@@ -50,51 +69,301 @@ func (a *App) Run() error {
 
 	// Инициализация репозиториев
 	postRepo := repository.NewPostRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	searchIndex := repository.NewMySQLSearchIndex(db)
+	revisionRepo := repository.NewRevisionRepository(db)
+	voteRepo := repository.NewVoteRepository(db)
+	reactionRepo := repository.NewReactionRepository(db)
+	bookmarkRepo := repository.NewBookmarkRepository(db)
+	subscriptionRepo := repository.NewSubscriptionRepository(db)
+	pollRepo := repository.NewPollRepository(db)
+	attachmentRepo := repository.NewAttachmentRepository(db)
+	mentionRepo := repository.NewMentionRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	avatarRepo := repository.NewAvatarRepository(db)
+
+	// Хранилище файлов
+	blobStore, err := storage.New(a.cfg.Storage)
+	if err != nil {
+		return err
+	}
 
 	// Инициализация сервисов
-	postService := service.NewPostService(postRepo)
+	userService := service.NewUserService(userRepo)
+	mentionService := service.NewMentionService(mentionRepo, postRepo, userRepo)
+	postService := service.NewPostService(postRepo, categoryRepo, mentionService)
+	commentService := service.NewCommentService(commentRepo, postRepo, mentionService)
+	categoryService := service.NewCategoryService(categoryRepo)
+	tagService := service.NewTagService(tagRepo)
+	searchService := service.NewSearchService(searchIndex)
+	revisionService := service.NewRevisionService(revisionRepo, postRepo)
+	voteService := service.NewVoteService(voteRepo, postRepo, commentRepo)
+	reactionService := service.NewReactionService(reactionRepo, postRepo, commentRepo, a.cfg.Reactions.Allowed)
+	bookmarkService := service.NewBookmarkService(bookmarkRepo, postRepo)
+	subscriptionService := service.NewSubscriptionService(subscriptionRepo, postRepo)
+	pollService := service.NewPollService(pollRepo, postRepo)
+	imageProcessor := service.NewImageProcessor(attachmentRepo, blobStore, a.cfg.Attachment.ImageInterval)
+	viewCounter := service.NewViewCounter(postRepo, a.cfg.Views.FlushInterval, a.cfg.Views.DedupWindow)
+	attachmentService := service.NewAttachmentService(attachmentRepo, postRepo, commentRepo, blobStore, imageProcessor, a.cfg.Attachment)
+	avatarService := service.NewAvatarService(avatarRepo, userRepo, blobStore, a.cfg.Avatar)
 
 	// Инициализация обработчиков
-	authController := controllers.NewAuthController(a.authClient)
-	postController := controllers.NewPostController(postService)
+	h := handlers{
+		auth:         controllers.NewAuthController(a.authClient, userService),
+		post:         controllers.NewPostController(postService, reactionService, bookmarkService, viewCounter, userService),
+		comment:      controllers.NewCommentController(commentService, userService),
+		category:     controllers.NewCategoryController(categoryService),
+		tag:          controllers.NewTagController(tagService),
+		search:       controllers.NewSearchController(searchService, userService),
+		revision:     controllers.NewRevisionController(revisionService),
+		vote:         controllers.NewVoteController(voteService),
+		reaction:     controllers.NewReactionController(reactionService),
+		bookmark:     controllers.NewBookmarkController(bookmarkService, userService),
+		subscription: controllers.NewSubscriptionController(subscriptionService, userService),
+		poll:         controllers.NewPollController(pollService),
+		attachment:   controllers.NewAttachmentController(attachmentService),
+		mention:      controllers.NewMentionController(mentionService, userService),
+		user:         controllers.NewUserController(userService),
+		avatar:       controllers.NewAvatarController(avatarService),
+	}
 
 	// Настройка маршрутов
-	a.setupRoutes(authController, postController)
+	a.setupRoutes(h)
+
+	// Фоновые задачи работают до получения сигнала завершения
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var wg sync.WaitGroup
+	runBackground(ctx, &wg, service.NewPostPurger(postRepo, a.cfg.Purge.Retention, a.cfg.Purge.Interval).Run)
+	runBackground(ctx, &wg, service.NewPostScheduler(postRepo, a.cfg.Scheduler.Interval).Run)
+	runBackground(ctx, &wg, service.NewHotRanker(voteRepo, a.cfg.Ranking.HotInterval, a.cfg.Ranking.HotWindow).Run)
+	runBackground(ctx, &wg, service.NewAttachmentSweeper(attachmentRepo, blobStore, a.cfg.Purge.Interval).Run)
+	runBackground(ctx, &wg, imageProcessor.Run)
+	runBackground(ctx, &wg, viewCounter.Run)
+	runBackground(ctx, &wg, service.NewContentRenderer(postRepo, commentRepo).Run)
 
 	// Запуск сервера
-	return a.router.Run(a.cfg.HTTP.Port)
+	err = a.serve(ctx)
+	stop()
+	wg.Wait()
+
+	// Просмотры, учтенные во время завершения обрабатываемых запросов
+	viewCounter.Flush()
+	return err
+}
+
+// serve запускает HTTP-сервер и останавливает его после отмены ctx,
+// дожидаясь завершения обрабатываемых запросов
+func (a *App) serve(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:    a.cfg.HTTP.Port,
+		Handler: a.router,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// runBackground запускает фоновую задачу, учитывая ее в wg
+func runBackground(ctx context.Context, wg *sync.WaitGroup, task func(context.Context)) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		task(ctx)
+	}()
+}
+
+// handlers объединяет обработчики HTTP-запросов приложения
+type handlers struct {
+	auth         *controllers.AuthController
+	post         *controllers.PostController
+	comment      *controllers.CommentController
+	category     *controllers.CategoryController
+	tag          *controllers.TagController
+	search       *controllers.SearchController
+	revision     *controllers.RevisionController
+	vote         *controllers.VoteController
+	reaction     *controllers.ReactionController
+	bookmark     *controllers.BookmarkController
+	subscription *controllers.SubscriptionController
+	poll         *controllers.PollController
+	attachment   *controllers.AttachmentController
+	mention      *controllers.MentionController
+	user         *controllers.UserController
+	avatar       *controllers.AvatarController
 }
 
 // setupRoutes настраивает маршруты приложения
-func (a *App) setupRoutes(authController *controllers.AuthController, postController *controllers.PostController) {
+func (a *App) setupRoutes(h handlers) {
 	// Группа API
 	api := a.router.Group("/api")
 	{
 		// Маршруты аутентификации
 		auth := api.Group("/auth")
 		{
-			auth.POST("/register", authController.Register)
-			auth.POST("/login", authController.Login)
-			auth.POST("/refresh", authController.RefreshTokens)
+			auth.POST("/register", h.auth.Register)
+			auth.POST("/login", h.auth.Login)
+			auth.POST("/refresh", h.auth.RefreshTokens)
 		}
 
 		// Публичные маршруты для постов
 		posts := api.Group("/posts")
+		posts.Use(middleware.OptionalAuthMiddleware(a.authClient))
+		{
+			posts.GET("/", h.post.GetAll)
+			posts.GET("/:id", h.post.GetByID)
+			posts.GET("/by-slug/:slug", h.post.GetBySlug)
+			posts.GET("/:id/comments", h.comment.GetByPostID)
+			posts.GET("/:id/comments/tree", h.comment.GetTree)
+			posts.GET("/:id/revisions", h.revision.GetHistory)
+			posts.GET("/:id/revisions/diff", h.revision.Diff)
+			posts.GET("/:id/revisions/:rev", h.revision.Get)
+			posts.GET("/:id/reactions", h.reaction.GetSummary)
+			posts.GET("/:id/reactions/:emoji", h.reaction.GetReactors)
+			posts.GET("/:id/comments/:comment_id/reactions", h.reaction.GetSummary)
+			posts.GET("/:id/comments/:comment_id/reactions/:emoji", h.reaction.GetReactors)
+			posts.GET("/:id/poll", h.poll.Get)
+			posts.GET("/:id/poll/results", h.poll.Results)
+			posts.GET("/:id/attachments", h.attachment.GetAll)
+			posts.GET("/:id/comments/:comment_id/attachments", h.attachment.GetAll)
+		}
+
+		// Содержимое вложений
+		attachments := api.Group("/attachments")
+		attachments.Use(middleware.OptionalAuthMiddleware(a.authClient))
+		{
+			attachments.GET("/:id", h.attachment.Download)
+		}
+
+		// Набор доступных реакций
+		api.GET("/reactions", h.reaction.GetAllowed)
+
+		// Публичные маршруты для разделов
+		categories := api.Group("/categories")
+		categories.Use(middleware.OptionalAuthMiddleware(a.authClient))
+		{
+			categories.GET("/", h.category.GetTree)
+			categories.GET("/:id/posts", h.post.GetByCategory)
+		}
+
+		// Публичные маршруты для тегов
+		tags := api.Group("/tags")
+		tags.Use(middleware.OptionalAuthMiddleware(a.authClient))
+		{
+			tags.GET("/", h.tag.GetAll)
+			tags.GET("/:name/posts", h.post.GetByTag)
+		}
+
+		// Публичные профили пользователей
+		users := api.Group("/users")
 		{
-			posts.GET("/", postController.GetAll)
-			posts.GET("/:id", postController.GetByID)
+			users.GET("/:id", h.user.GetByID)
+			users.GET("/by-username/:name", h.user.GetByUsername)
+			users.GET("/:id/avatar", h.avatar.Get)
 		}
 
+		// Полнотекстовый поиск
+		api.GET("/search", h.search.Search)
+
 		// Защищенные маршруты
 		authorized := api.Group("/")
-		authorized.Use(middleware.AuthMiddleware(a.authClient))
+		authorized.Use(
+			middleware.AuthMiddleware(a.authClient),
+			middleware.ModeratorMiddleware(a.cfg.Moderation.ModeratorIDs),
+		)
 		{
 			// Защищенные маршруты для постов
 			authorizedPosts := authorized.Group("/posts")
 			{
-				authorizedPosts.POST("/", postController.Create)
-				authorizedPosts.PUT("/:id", postController.Update)
-				authorizedPosts.DELETE("/:id", postController.Delete)
+				authorizedPosts.POST("/", h.post.Create)
+				authorizedPosts.PUT("/:id", h.post.Update)
+				authorizedPosts.DELETE("/:id", h.post.Delete)
+				authorizedPosts.POST("/:id/restore", h.post.Restore)
+
+				// Защищенные маршруты для комментариев
+				authorizedPosts.POST("/:id/comments", h.comment.Create)
+				authorizedPosts.PUT("/:id/comments/:comment_id", h.comment.Update)
+				authorizedPosts.DELETE("/:id/comments/:comment_id", h.comment.Delete)
+
+				// Голосование за посты и комментарии
+				authorizedPosts.PUT("/:id/vote", h.vote.VotePost)
+				authorizedPosts.DELETE("/:id/vote", h.vote.RetractPostVote)
+				authorizedPosts.PUT("/:id/comments/:comment_id/vote", h.vote.VoteComment)
+				authorizedPosts.DELETE("/:id/comments/:comment_id/vote", h.vote.RetractCommentVote)
+
+				// Реакции на посты и комментарии
+				authorizedPosts.PUT("/:id/reactions/:emoji", h.reaction.React)
+				authorizedPosts.DELETE("/:id/reactions/:emoji", h.reaction.Unreact)
+				authorizedPosts.PUT("/:id/comments/:comment_id/reactions/:emoji", h.reaction.React)
+				authorizedPosts.DELETE("/:id/comments/:comment_id/reactions/:emoji", h.reaction.Unreact)
+
+				// Закладки
+				authorizedPosts.POST("/:id/bookmark", h.bookmark.Save)
+				authorizedPosts.DELETE("/:id/bookmark", h.bookmark.Delete)
+
+				// Подписки на темы
+				authorizedPosts.GET("/:id/subscription", h.subscription.Get)
+				authorizedPosts.PUT("/:id/subscription", h.subscription.Set)
+				authorizedPosts.DELETE("/:id/subscription", h.subscription.Delete)
+
+				// Опросы
+				authorizedPosts.POST("/:id/poll", h.poll.Create)
+				authorizedPosts.POST("/:id/poll/vote", h.poll.Vote)
+				authorizedPosts.POST("/:id/poll/close", h.poll.Close)
+
+				// Вложения
+				authorizedPosts.POST("/:id/attachments", h.attachment.Upload)
+				authorizedPosts.POST("/:id/comments/:comment_id/attachments", h.attachment.Upload)
+			}
+
+			// Защищенные маршруты для вложений
+			authorizedAttachments := authorized.Group("/attachments")
+			{
+				authorizedAttachments.DELETE("/:id", h.attachment.Delete)
+			}
+
+			// Маршруты модераторов для управления темами
+			moderatedPosts := authorized.Group("/posts")
+			moderatedPosts.Use(middleware.RequireModerator())
+			{
+				moderatedPosts.POST("/:id/pin", h.post.Pin)
+				moderatedPosts.DELETE("/:id/pin", h.post.Unpin)
+				moderatedPosts.POST("/:id/lock", h.post.Lock)
+				moderatedPosts.DELETE("/:id/lock", h.post.Unlock)
+				moderatedPosts.POST("/:id/announcement", h.post.Announce)
+				moderatedPosts.DELETE("/:id/announcement", h.post.Unannounce)
+			}
+
+			// Личные разделы пользователя
+			me := authorized.Group("/me")
+			{
+				me.GET("/drafts", h.post.GetDrafts)
+				me.GET("/trash", h.post.GetTrash)
+				me.GET("/bookmarks", h.bookmark.GetMine)
+				me.GET("/bookmarks/folders", h.bookmark.GetFolders)
+				me.GET("/subscriptions", h.subscription.GetMine)
+				me.GET("/mentions", h.mention.GetMine)
+				me.PUT("/avatar", h.avatar.Upload)
+				me.DELETE("/avatar", h.avatar.Delete)
 			}
 		}
 	}
@@ -3,15 +3,27 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	DB   DBConfig
-	HTTP HTTPConfig
-	JWT  JWTConfig
-	Auth AuthConfig
+	DB         DBConfig
+	HTTP       HTTPConfig
+	JWT        JWTConfig
+	Auth       AuthConfig
+	Moderation ModerationConfig
+	Purge      PurgeConfig
+	Scheduler  SchedulerConfig
+	Ranking    RankingConfig
+	Reactions  ReactionsConfig
+	Storage    StorageConfig
+	Attachment AttachmentConfig
+	Avatar     AvatarConfig
+	Views      ViewsConfig
 }
 
 type DBConfig struct {
@@ -24,6 +36,9 @@ type DBConfig struct {
 
 type HTTPConfig struct {
 	Port string
+	// TrustedProxies — адреса и подсети прокси, которым доверяется заголовок
+	// X-Forwarded-For. Если список пуст, адрес клиента берется из соединения.
+	TrustedProxies []string
 }
 
 type JWTConfig struct {
@@ -34,12 +49,149 @@ type AuthConfig struct {
 	GrpcAddress string
 }
 
+type ModerationConfig struct {
+	// ModeratorIDs содержит идентификаторы пользователей с правами модератора
+	ModeratorIDs []int64
+}
+
+type PurgeConfig struct {
+	// Retention — срок хранения удаленных постов до окончательного удаления
+	Retention time.Duration
+	// Interval — период запуска очистки корзины
+	Interval time.Duration
+}
+
+type SchedulerConfig struct {
+	// Interval — период проверки отложенных постов, готовых к публикации
+	Interval time.Duration
+}
+
+type RankingConfig struct {
+	// HotInterval — период пересчета рейтинга "hot"
+	HotInterval time.Duration
+	// HotWindow — возраст постов, для которых рейтинг "hot" пересчитывается
+	HotWindow time.Duration
+}
+
+type ReactionsConfig struct {
+	// Allowed — набор эмодзи, доступных для реакций
+	Allowed []string
+}
+
+type StorageConfig struct {
+	// Backend — хранилище файлов: local или s3
+	Backend string
+	// LocalDir — каталог файлов для хранилища local
+	LocalDir string
+	S3       S3Config
+}
+
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool
+}
+
+type AttachmentConfig struct {
+	// MaxSize — максимальный размер одного файла в байтах
+	MaxSize int64
+	// UserQuota — суммарный размер файлов одного пользователя в байтах
+	UserQuota int64
+	// AllowedTypes — допустимые MIME-типы, определяемые по содержимому файла.
+	// Из изображений обрабатываются и очищаются от метаданных только JPEG,
+	// PNG и GIF.
+	AllowedTypes []string
+	// ImageInterval — период проверки необработанных изображений
+	ImageInterval time.Duration
+}
+
+type AvatarConfig struct {
+	// MaxSize — максимальный размер загружаемого аватара в байтах
+	MaxSize int64
+}
+
+type ViewsConfig struct {
+	// FlushInterval — период записи накопленных просмотров в базу
+	FlushInterval time.Duration
+	// DedupWindow — интервал, в течение которого повторные просмотры поста
+	// одним посетителем не учитываются
+	DedupWindow time.Duration
+}
+
 func LoadConfig() (*Config, error) {
 	if err := godotenv.Load(); err != nil {
 		// Если .env файл не найден, продолжаем с переменными окружения
 		fmt.Printf("[WARNING] .env file not found: %s\n", err.Error())
 	}
 
+	moderatorIDs, err := getEnvIDs("MODERATOR_IDS")
+	if err != nil {
+		return nil, err
+	}
+
+	purgeRetention, err := getEnvDuration("PURGE_RETENTION", 30*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	purgeInterval, err := getEnvDuration("PURGE_INTERVAL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	schedulerInterval, err := getEnvDuration("SCHEDULER_INTERVAL", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	hotInterval, err := getEnvDuration("HOT_RANK_INTERVAL", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	hotWindow, err := getEnvDuration("HOT_RANK_WINDOW", 30*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	attachmentMaxSize, err := getEnvInt64("ATTACHMENT_MAX_SIZE", 10<<20)
+	if err != nil {
+		return nil, err
+	}
+
+	attachmentQuota, err := getEnvInt64("ATTACHMENT_USER_QUOTA", 200<<20)
+	if err != nil {
+		return nil, err
+	}
+
+	imageInterval, err := getEnvDuration("IMAGE_PROCESS_INTERVAL", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	avatarMaxSize, err := getEnvInt64("AVATAR_MAX_SIZE", 5<<20)
+	if err != nil {
+		return nil, err
+	}
+
+	viewFlushInterval, err := getEnvDuration("VIEW_FLUSH_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	viewDedupWindow, err := getEnvDuration("VIEW_DEDUP_WINDOW", 30*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	s3PathStyle, err := getEnvBool("S3_PATH_STYLE", false)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Config{
 		DB: DBConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -49,7 +201,8 @@ func LoadConfig() (*Config, error) {
 			Name:     getEnv("DB_NAME", "forum"),
 		},
 		HTTP: HTTPConfig{
-			Port: getEnv("HTTP_PORT", ":8080"),
+			Port:           getEnv("HTTP_PORT", ":8080"),
+			TrustedProxies: getEnvList("TRUSTED_PROXIES", ""),
 		},
 		JWT: JWTConfig{
 			SecretKey: getEnv("JWT_SECRET", "your-secret-key"),
@@ -57,6 +210,49 @@ func LoadConfig() (*Config, error) {
 		Auth: AuthConfig{
 			GrpcAddress: getEnv("AUTH_GRPC_ADDRESS", "localhost:50051"),
 		},
+		Moderation: ModerationConfig{
+			ModeratorIDs: moderatorIDs,
+		},
+		Purge: PurgeConfig{
+			Retention: purgeRetention,
+			Interval:  purgeInterval,
+		},
+		Scheduler: SchedulerConfig{
+			Interval: schedulerInterval,
+		},
+		Ranking: RankingConfig{
+			HotInterval: hotInterval,
+			HotWindow:   hotWindow,
+		},
+		Reactions: ReactionsConfig{
+			Allowed: getEnvList("REACTIONS_ALLOWED", "👍,👎,❤️,😂,😮,😢,🎉"),
+		},
+		Storage: StorageConfig{
+			Backend:  getEnv("STORAGE_BACKEND", "local"),
+			LocalDir: getEnv("STORAGE_LOCAL_DIR", "uploads"),
+			S3: S3Config{
+				Endpoint:  getEnv("S3_ENDPOINT", ""),
+				Region:    getEnv("S3_REGION", "us-east-1"),
+				Bucket:    getEnv("S3_BUCKET", ""),
+				AccessKey: getEnv("S3_ACCESS_KEY", ""),
+				SecretKey: getEnv("S3_SECRET_KEY", ""),
+				PathStyle: s3PathStyle,
+			},
+		},
+		Attachment: AttachmentConfig{
+			MaxSize:   attachmentMaxSize,
+			UserQuota: attachmentQuota,
+			AllowedTypes: getEnvList("ATTACHMENT_ALLOWED_TYPES",
+				"image/jpeg,image/png,image/gif,application/pdf,text/plain,application/zip"),
+			ImageInterval: imageInterval,
+		},
+		Avatar: AvatarConfig{
+			MaxSize: avatarMaxSize,
+		},
+		Views: ViewsConfig{
+			FlushInterval: viewFlushInterval,
+			DedupWindow:   viewDedupWindow,
+		},
 	}, nil
 }
 
@@ -66,3 +262,80 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	// Интервалы передаются в time.NewTicker, который не принимает
+	// нулевые и отрицательные значения
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid %s: must be positive, got %s", key, value)
+	}
+	return d, nil
+}
+
+func getEnvInt64(key string, defaultValue int64) (int64, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue, nil
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	// Размеры и квоты: при нуле любая загрузка отклонялась бы
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid %s: must be positive, got %s", key, value)
+	}
+	return n, nil
+}
+
+func getEnvBool(key string, defaultValue bool) (bool, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return b, nil
+}
+
+// getEnvList разбирает список значений, разделенных запятыми
+func getEnvList(key, defaultValue string) []string {
+	var values []string
+	for _, part := range strings.Split(getEnv(key, defaultValue), ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+// getEnvIDs разбирает список идентификаторов, разделенных запятыми
+func getEnvIDs(key string) ([]int64, error) {
+	var ids []int64
+	for _, part := range strings.Split(getEnv(key, ""), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AttachmentController struct {
+	service *service.AttachmentService
+}
+
+func NewAttachmentController(service *service.AttachmentService) *AttachmentController {
+	return &AttachmentController{service: service}
+}
+
+// Upload прикрепляет файл из поля file multipart-формы к посту или комментарию
+func (h *AttachmentController) Upload(c *gin.Context) {
+	postID, commentID, ok := targetParams(c)
+	if !ok {
+		return
+	}
+
+	header, ok := formFile(c, h.service.MaxSize(), service.ErrAttachmentTooLarge)
+	if !ok {
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.service.Upload(c.Request.Context(), postID, commentID, viewerID(c), c.GetBool("is_moderator"), service.Upload{
+		Filename: header.Filename,
+		Size:     header.Size,
+		Content:  file,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// GetAll возвращает вложения поста или комментария
+func (h *AttachmentController) GetAll(c *gin.Context) {
+	postID, commentID, ok := targetParams(c)
+	if !ok {
+		return
+	}
+
+	var (
+		attachments []*model.Attachment
+		err         error
+	)
+	if commentID != 0 {
+		attachments, err = h.service.GetByCommentID(postID, commentID, viewerID(c))
+	} else {
+		attachments, err = h.service.GetByPostID(postID, viewerID(c))
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+// Download отдает содержимое вложения или его уменьшенной копии, указанной
+// в параметре size. Изображения показываются в браузере, остальные файлы
+// скачиваются.
+func (h *AttachmentController) Download(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	content, err := h.service.Open(c.Request.Context(), id, viewerID(c), c.Query("size"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer content.Content.Close()
+
+	disposition := "attachment"
+	if strings.HasPrefix(content.ContentType, "image/") {
+		disposition = "inline"
+	}
+
+	c.DataFromReader(http.StatusOK, content.Size, content.ContentType, content.Content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType(disposition, map[string]string{"filename": content.Filename}),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, max-age=86400",
+	})
+}
+
+func (h *AttachmentController) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	if err := h.service.Delete(id, viewerID(c), c.GetBool("is_moderator")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
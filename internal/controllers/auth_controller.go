@@ -1,19 +1,24 @@
 package controllers
 
 import (
+	"log"
 	"net/http"
 
 	"github.com/fire9900/golang-forum/internal/auth"
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
 type AuthController struct {
 	authClient *auth.GrpcAuthClient
+	users      *service.UserService
 }
 
-func NewAuthController(authClient *auth.GrpcAuthClient) *AuthController {
+func NewAuthController(authClient *auth.GrpcAuthClient, users *service.UserService) *AuthController {
 	return &AuthController{
 		authClient: authClient,
+		users:      users,
 	}
 }
 
@@ -44,6 +49,7 @@ func (c *AuthController) Register(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	c.syncUser(response)
 
 	ctx.JSON(http.StatusCreated, response)
 }
@@ -60,6 +66,7 @@ func (c *AuthController) Login(ctx *gin.Context) {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
+	c.syncUser(response)
 
 	ctx.JSON(http.StatusOK, response)
 }
@@ -79,3 +86,20 @@ func (c *AuthController) RefreshTokens(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, response)
 }
+
+// syncUser сохраняет пользователя из ответа сервиса авторизации в локальный
+// каталог. Ошибка сохранения не мешает входу и только записывается в журнал.
+func (c *AuthController) syncUser(response *auth.AuthResponse) {
+	if response.User == nil || response.User.ID == 0 {
+		return
+	}
+
+	err := c.users.Sync(&model.User{
+		ID:       response.User.ID,
+		Username: response.User.Username,
+		Email:    response.User.Email,
+	})
+	if err != nil {
+		log.Printf("Ошибка сохранения пользователя %d: %s\n", response.User.ID, err.Error())
+	}
+}
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fire9900/golang-forum/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AvatarController struct {
+	service *service.AvatarService
+}
+
+func NewAvatarController(service *service.AvatarService) *AvatarController {
+	return &AvatarController{service: service}
+}
+
+// Upload заменяет аватар текущего пользователя изображением из поля file
+// multipart-формы
+func (h *AvatarController) Upload(c *gin.Context) {
+	header, ok := formFile(c, h.service.MaxSize(), service.ErrAvatarTooLarge)
+	if !ok {
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer file.Close()
+
+	userID := viewerID(c)
+	err = h.service.Upload(c.Request.Context(), userID, service.Upload{
+		Filename: header.Filename,
+		Size:     header.Size,
+		Content:  file,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Delete удаляет аватар текущего пользователя
+func (h *AvatarController) Delete(c *gin.Context) {
+	if err := h.service.Delete(c.Request.Context(), viewerID(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Get отдает аватар пользователя со стороной из параметра size
+func (h *AvatarController) Get(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	content, err := h.service.Open(c.Request.Context(), id, c.Query("size"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer content.Content.Close()
+
+	// Адрес аватара не меняется при загрузке нового, поэтому кэш короткий
+	c.DataFromReader(http.StatusOK, content.Size, content.ContentType, content.Content, map[string]string{
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "public, max-age=300",
+	})
+}
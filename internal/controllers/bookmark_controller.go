@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BookmarkController struct {
+	service *service.BookmarkService
+	users   *service.UserService
+}
+
+func NewBookmarkController(service *service.BookmarkService, users *service.UserService) *BookmarkController {
+	return &BookmarkController{service: service, users: users}
+}
+
+// Save добавляет пост в закладки. Тело запроса с папкой и заметкой необязательно.
+func (h *BookmarkController) Save(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	var req model.BookmarkRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	bookmark, err := h.service.Save(viewerID(c), postID, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !h.attachAuthors(c, []*model.Bookmark{bookmark}) {
+		return
+	}
+
+	c.JSON(http.StatusOK, bookmark)
+}
+
+func (h *BookmarkController) Delete(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	if err := h.service.Delete(viewerID(c), postID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetMine возвращает закладки пользователя. Параметр folder ограничивает
+// выборку одной папкой; пустое значение выбирает закладки без папки.
+func (h *BookmarkController) GetMine(c *gin.Context) {
+	req, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	var folder *string
+	if value, ok := c.GetQuery("folder"); ok {
+		folder = &value
+	}
+
+	bookmarks, err := h.service.GetByUser(viewerID(c), folder, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !h.attachAuthors(c, bookmarks.Items) {
+		return
+	}
+
+	respondPage(c, bookmarks)
+}
+
+func (h *BookmarkController) GetFolders(c *gin.Context) {
+	folders, err := h.service.GetFolders(viewerID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, folders)
+}
+
+// attachAuthors встраивает авторов в посты из закладок
+func (h *BookmarkController) attachAuthors(c *gin.Context, bookmarks []*model.Bookmark) bool {
+	return attachAuthors(c, func() error { return h.users.AttachBookmarkAuthors(bookmarks) })
+}
@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/fire9900/golang-forum/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryController struct {
+	service *service.CategoryService
+}
+
+func NewCategoryController(service *service.CategoryService) *CategoryController {
+	return &CategoryController{service: service}
+}
+
+func (h *CategoryController) GetTree(c *gin.Context) {
+	categories, err := h.service.GetTree()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
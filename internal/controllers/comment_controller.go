@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommentController struct {
+	service *service.CommentService
+	users   *service.UserService
+}
+
+func NewCommentController(service *service.CommentService, users *service.UserService) *CommentController {
+	return &CommentController{service: service, users: users}
+}
+
+type commentRequest struct {
+	Content string `json:"content" binding:"required,max=10000"`
+}
+
+type createCommentRequest struct {
+	commentRequest
+	ParentID *int64 `json:"parent_id"`
+}
+
+func (h *CommentController) GetByPostID(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+
+	comments, err := h.service.GetByPostID(postID, page, perPage)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if !h.attachAuthors(c, comments) {
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+func (h *CommentController) GetTree(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	depth, _ := strconv.Atoi(c.Query("depth"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	tree, err := h.service.GetTree(postID, service.TreeOptions{
+		Depth:  depth,
+		Limit:  limit,
+		Cursor: c.Query("cursor"),
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if !h.attachAuthors(c, flattenTree(tree.Comments, nil)) {
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
+func (h *CommentController) Create(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	var req createCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	comment := model.Comment{
+		Content:  req.Content,
+		PostID:   postID,
+		ParentID: req.ParentID,
+		AuthorID: userID.(int64),
+	}
+
+	if err := h.service.Create(&comment, c.GetBool("is_moderator")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if !h.attachAuthors(c, []*model.Comment{&comment}) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+func (h *CommentController) Update(c *gin.Context) {
+	postID, commentID, ok := commentParams(c)
+	if !ok {
+		return
+	}
+
+	var req commentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	comment := model.Comment{
+		ID:       commentID,
+		Content:  req.Content,
+		PostID:   postID,
+		AuthorID: userID.(int64),
+	}
+
+	if err := h.service.Update(&comment); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if !h.attachAuthors(c, []*model.Comment{&comment}) {
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+func (h *CommentController) Delete(c *gin.Context) {
+	postID, commentID, ok := commentParams(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := h.service.Delete(commentID, postID, userID.(int64)); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// commentParams разбирает идентификаторы поста и комментария из пути запроса
+func commentParams(c *gin.Context) (postID, commentID int64, ok bool) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return 0, 0, false
+	}
+
+	commentID, err = strconv.ParseInt(c.Param("comment_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment_id parameter"})
+		return 0, 0, false
+	}
+
+	return postID, commentID, true
+}
+
+// attachAuthors встраивает в комментарии их авторов
+func (h *CommentController) attachAuthors(c *gin.Context, comments []*model.Comment) bool {
+	return attachAuthors(c, func() error { return h.users.AttachCommentAuthors(comments) })
+}
+
+// flattenTree добавляет к comments комментарии дерева nodes со всеми ответами
+func flattenTree(nodes []*model.CommentNode, comments []*model.Comment) []*model.Comment {
+	for _, node := range nodes {
+		comments = append(comments, &node.Comment)
+		comments = flattenTree(node.Replies, comments)
+	}
+	return comments
+}
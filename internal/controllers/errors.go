@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/fire9900/golang-forum/internal/httputil"
+	"github.com/fire9900/golang-forum/internal/pagination"
+	"github.com/fire9900/golang-forum/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errorStatus сопоставляет ошибки сервисного слоя с HTTP-статусами
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrPostNotFound),
+		errors.Is(err, service.ErrCommentNotFound),
+		errors.Is(err, service.ErrCategoryNotFound),
+		errors.Is(err, service.ErrRevisionNotFound),
+		errors.Is(err, service.ErrBookmarkNotFound),
+		errors.Is(err, service.ErrSubscriptionNotFound),
+		errors.Is(err, service.ErrPollNotFound),
+		errors.Is(err, service.ErrAttachmentNotFound),
+		errors.Is(err, service.ErrUserNotFound),
+		errors.Is(err, service.ErrAvatarNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrParentNotFound),
+		errors.Is(err, service.ErrInvalidCursor),
+		errors.Is(err, pagination.ErrInvalidCursor),
+		errors.Is(err, service.ErrInvalidCategory):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrPostLocked),
+		errors.Is(err, service.ErrPollForbidden),
+		errors.Is(err, service.ErrPollResultsHidden),
+		errors.Is(err, service.ErrAttachmentForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrPollExists),
+		errors.Is(err, service.ErrPollClosed),
+		errors.Is(err, service.ErrAlreadyVoted):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrAttachmentTooLarge),
+		errors.Is(err, service.ErrQuotaExceeded),
+		errors.Is(err, service.ErrAvatarTooLarge):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// respondError отправляет клиенту ошибку сервисного слоя с подходящим статусом
+func respondError(c *gin.Context, err error) {
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusBadRequest, httputil.NewValidationError(validationErr.Details))
+		return
+	}
+
+	c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/fire9900/golang-forum/internal/httputil"
+
+	"github.com/gin-gonic/gin"
+)
+
+// includeAuthor разбирает параметр include — список встраиваемых в ответ
+// связанных объектов через запятую. Без параметра авторы встраиваются,
+// а include без author отключает их. При ошибке отвечает клиенту 400 и
+// возвращает ok = false.
+func includeAuthor(c *gin.Context) (include, ok bool) {
+	values, present := c.GetQueryArray("include")
+	if !present {
+		return true, true
+	}
+
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			switch strings.TrimSpace(name) {
+			case "":
+			case "author":
+				include = true
+			default:
+				c.JSON(http.StatusBadRequest, httputil.NewValidationError(map[string]string{
+					"include": "must be a comma-separated list of: author",
+				}))
+				return false, false
+			}
+		}
+	}
+	return include, true
+}
+
+// attachAuthors встраивает авторов функцией attach, если клиент не
+// отказался от этого параметром include. При ошибке отправляет ответ и
+// возвращает false.
+func attachAuthors(c *gin.Context, attach func() error) bool {
+	include, ok := includeAuthor(c)
+	if !ok || !include {
+		return ok
+	}
+
+	if err := attach(); err != nil {
+		respondError(c, err)
+		return false
+	}
+	return true
+}
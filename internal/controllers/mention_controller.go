@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"github.com/fire9900/golang-forum/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MentionController struct {
+	service *service.MentionService
+	users   *service.UserService
+}
+
+func NewMentionController(service *service.MentionService, users *service.UserService) *MentionController {
+	return &MentionController{service: service, users: users}
+}
+
+// GetMine возвращает упоминания текущего пользователя, начиная с новых
+func (h *MentionController) GetMine(c *gin.Context) {
+	req, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	mentions, err := h.service.GetMine(viewerID(c), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !attachAuthors(c, func() error { return h.users.AttachMentionAuthors(mentions.Items) }) {
+		return
+	}
+
+	respondPage(c, mentions)
+}
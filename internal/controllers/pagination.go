@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fire9900/golang-forum/internal/httputil"
+	"github.com/fire9900/golang-forum/internal/pagination"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pageRequest разбирает параметры страницы per_page, after и before
+func pageRequest(c *gin.Context) (pagination.Request, bool) {
+	req := pagination.Request{
+		After:  c.Query("after"),
+		Before: c.Query("before"),
+		Limit:  pagination.DefaultLimit,
+	}
+
+	if value, ok := c.GetQuery("per_page"); ok {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, httputil.NewValidationError(map[string]string{
+				"per_page": "must be an integer",
+			}))
+			return req, false
+		}
+		req.Limit = limit
+	}
+
+	return req, true
+}
+
+// respondPage отправляет страницу списка вместе с заголовком Link
+func respondPage[T any](c *gin.Context, page pagination.Page[T]) {
+	httputil.SetLinkHeader(c, page.NextCursor, page.PrevCursor)
+	c.JSON(http.StatusOK, page)
+}
@@ -3,7 +3,9 @@ package controllers
 import (
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/fire9900/golang-forum/internal/httputil"
 	"github.com/fire9900/golang-forum/internal/model"
 	"github.com/fire9900/golang-forum/internal/service"
 
@@ -11,11 +13,21 @@ import (
 )
 
 type PostController struct {
-	service *service.PostService
+	service   *service.PostService
+	reactions *service.ReactionService
+	bookmarks *service.BookmarkService
+	views     *service.ViewCounter
+	users     *service.UserService
 }
 
-func NewPostController(service *service.PostService) *PostController {
-	return &PostController{service: service}
+func NewPostController(
+	service *service.PostService,
+	reactions *service.ReactionService,
+	bookmarks *service.BookmarkService,
+	views *service.ViewCounter,
+	users *service.UserService,
+) *PostController {
+	return &PostController{service: service, reactions: reactions, bookmarks: bookmarks, views: views, users: users}
 }
 
 func (h *PostController) Create(c *gin.Context) {
@@ -30,7 +42,11 @@ func (h *PostController) Create(c *gin.Context) {
 	post.AuthorID = userID.(int64)
 
 	if err := h.service.Create(&post); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
+		return
+	}
+
+	if !h.attachAuthors(c, []*model.Post{&post}) {
 		return
 	}
 
@@ -50,20 +66,149 @@ func (h *PostController) GetByID(c *gin.Context) {
 		return
 	}
 
+	h.respondPost(c, post)
+}
+
+// GetBySlug возвращает пост по slug. Запрос по прежнему slug
+// перенаправляется на адрес с текущим.
+func (h *PostController) GetBySlug(c *gin.Context) {
+	post, err := h.service.GetBySlug(c.Param("slug"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if post.Slug != c.Param("slug") {
+		location := "/api/posts/by-slug/" + post.Slug
+		if c.Request.URL.RawQuery != "" {
+			location += "?" + c.Request.URL.RawQuery
+		}
+		c.Redirect(http.StatusMovedPermanently, location)
+		return
+	}
+
+	h.respondPost(c, post)
+}
+
+// respondPost отвечает постом вместе с реакциями и отметкой о закладке
+// и учитывает его просмотр
+func (h *PostController) respondPost(c *gin.Context, post *model.Post) {
+	// Анонимные посетители различаются по IP-адресу
+	viewer := "ip:" + c.ClientIP()
+	if userID := viewerID(c); userID != 0 {
+		viewer = "user:" + strconv.FormatInt(userID, 10)
+	}
+	h.views.Record(post.ID, viewer)
+
+	var err error
+	post.Reactions, err = h.reactions.Summary(post.ID, 0, viewerID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if !h.markBookmarked(c, []*model.Post{post}) {
+		return
+	}
+
+	if !h.attachAuthors(c, []*model.Post{post}) {
+		return
+	}
+
 	c.JSON(http.StatusOK, post)
 }
 
 func (h *PostController) GetAll(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+	req, ok := pageRequest(c)
+	if !ok {
+		return
+	}
 
-	posts, err := h.service.GetAll(page, perPage)
+	filter, ok := postFilter(c)
+	if !ok {
+		return
+	}
+
+	posts, err := h.service.GetAll(filter, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, posts)
+	if !h.markBookmarked(c, posts.Items) {
+		return
+	}
+
+	if !h.attachAuthors(c, posts.Items) {
+		return
+	}
+
+	respondPage(c, posts)
+}
+
+func (h *PostController) GetByCategory(c *gin.Context) {
+	categoryID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	req, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	filter, ok := postFilter(c)
+	if !ok {
+		return
+	}
+	filter.CategoryID = categoryID
+
+	posts, err := h.service.GetByCategory(filter, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if !h.markBookmarked(c, posts.Items) {
+		return
+	}
+
+	if !h.attachAuthors(c, posts.Items) {
+		return
+	}
+
+	respondPage(c, posts)
+}
+
+func (h *PostController) GetByTag(c *gin.Context) {
+	req, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	filter, ok := postFilter(c)
+	if !ok {
+		return
+	}
+	filter.Tags = append(filter.Tags, c.Param("name"))
+	filter.TagMode = model.TagModeAnd
+
+	posts, err := h.service.GetAll(filter, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if !h.markBookmarked(c, posts.Items) {
+		return
+	}
+
+	if !h.attachAuthors(c, posts.Items) {
+		return
+	}
+
+	respondPage(c, posts)
 }
 
 func (h *PostController) Update(c *gin.Context) {
@@ -84,7 +229,11 @@ func (h *PostController) Update(c *gin.Context) {
 	post.AuthorID = userID.(int64)
 
 	if err := h.service.Update(&post); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
+		return
+	}
+
+	if !h.attachAuthors(c, []*model.Post{&post}) {
 		return
 	}
 
@@ -99,10 +248,181 @@ func (h *PostController) Delete(c *gin.Context) {
 	}
 
 	userID, _ := c.Get("user_id")
-	if err := h.service.Delete(id, userID.(int64)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.Delete(id, userID.(int64), c.GetBool("is_moderator")); err != nil {
+		respondError(c, err)
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
+
+func (h *PostController) Restore(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	post, err := h.service.Restore(id, userID.(int64), c.GetBool("is_moderator"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !h.attachAuthors(c, []*model.Post{post}) {
+		return
+	}
+
+	c.JSON(http.StatusOK, post)
+}
+
+type pinRequest struct {
+	Scope string `json:"scope"`
+}
+
+func (h *PostController) Pin(c *gin.Context) {
+	var req pinRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	h.updateFlags(c, func(id int64) (*model.Post, error) { return h.service.Pin(id, req.Scope) })
+}
+
+func (h *PostController) Unpin(c *gin.Context) {
+	h.updateFlags(c, h.service.Unpin)
+}
+
+func (h *PostController) Lock(c *gin.Context) {
+	h.updateFlags(c, func(id int64) (*model.Post, error) { return h.service.SetLocked(id, true) })
+}
+
+func (h *PostController) Unlock(c *gin.Context) {
+	h.updateFlags(c, func(id int64) (*model.Post, error) { return h.service.SetLocked(id, false) })
+}
+
+func (h *PostController) Announce(c *gin.Context) {
+	h.updateFlags(c, func(id int64) (*model.Post, error) { return h.service.SetAnnouncement(id, true) })
+}
+
+func (h *PostController) Unannounce(c *gin.Context) {
+	h.updateFlags(c, func(id int64) (*model.Post, error) { return h.service.SetAnnouncement(id, false) })
+}
+
+// updateFlags меняет состояние темы и возвращает обновленный пост
+func (h *PostController) updateFlags(c *gin.Context, update func(id int64) (*model.Post, error)) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	post, err := update(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !h.attachAuthors(c, []*model.Post{post}) {
+		return
+	}
+
+	c.JSON(http.StatusOK, post)
+}
+
+func (h *PostController) GetDrafts(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	posts, err := h.service.GetDrafts(userID.(int64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !h.attachAuthors(c, posts) {
+		return
+	}
+
+	c.JSON(http.StatusOK, posts)
+}
+
+func (h *PostController) GetTrash(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	posts, err := h.service.GetTrash(userID.(int64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !h.attachAuthors(c, posts) {
+		return
+	}
+
+	c.JSON(http.StatusOK, posts)
+}
+
+// postFilter собирает условия выборки постов из параметров запроса.
+// При ошибке разбора параметров отвечает клиенту 400 и возвращает false.
+func postFilter(c *gin.Context) (model.PostFilter, bool) {
+	filter := model.PostFilter{
+		Tags:    c.QueryArray("tag"),
+		TagMode: c.Query("tag_mode"),
+		Title:   c.Query("title"),
+		Sort:    c.Query("sort"),
+	}
+	details := make(map[string]string)
+
+	if value := c.Query("author_id"); value != "" {
+		authorID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			details["author_id"] = "must be an integer"
+		}
+		filter.AuthorID = authorID
+	}
+
+	timeParams := map[string]**time.Time{
+		"created_after":  &filter.CreatedAfter,
+		"created_before": &filter.CreatedBefore,
+		"updated_after":  &filter.UpdatedAfter,
+		"updated_before": &filter.UpdatedBefore,
+	}
+	for name, dst := range timeParams {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			details[name] = "must be an RFC 3339 timestamp"
+			continue
+		}
+		*dst = &t
+	}
+
+	if len(details) > 0 {
+		c.JSON(http.StatusBadRequest, httputil.NewValidationError(details))
+		return filter, false
+	}
+	return filter, true
+}
+
+// markBookmarked отмечает посты из закладок аутентифицированного
+// пользователя. При ошибке отправляет ответ и возвращает false.
+func (h *PostController) markBookmarked(c *gin.Context, posts []*model.Post) bool {
+	userID := viewerID(c)
+	if userID == 0 {
+		return true
+	}
+
+	if err := h.bookmarks.MarkBookmarked(userID, posts); err != nil {
+		respondError(c, err)
+		return false
+	}
+	return true
+}
+
+// attachAuthors встраивает в посты их авторов
+func (h *PostController) attachAuthors(c *gin.Context, posts []*model.Post) bool {
+	return attachAuthors(c, func() error { return h.users.AttachPostAuthors(posts) })
+}
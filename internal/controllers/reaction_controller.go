@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fire9900/golang-forum/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReactionController struct {
+	service *service.ReactionService
+}
+
+func NewReactionController(service *service.ReactionService) *ReactionController {
+	return &ReactionController{service: service}
+}
+
+// GetAllowed возвращает набор разрешенных эмодзи
+func (h *ReactionController) GetAllowed(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"emoji": h.service.Allowed()})
+}
+
+// GetSummary возвращает сводку реакций на пост или комментарий
+func (h *ReactionController) GetSummary(c *gin.Context) {
+	postID, commentID, ok := targetParams(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.service.Summary(postID, commentID, viewerID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// GetReactors возвращает пользователей, оставивших реакцию с эмодзи из пути
+func (h *ReactionController) GetReactors(c *gin.Context) {
+	postID, commentID, ok := targetParams(c)
+	if !ok {
+		return
+	}
+
+	req, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	reactors, err := h.service.Reactors(postID, commentID, c.Param("emoji"), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondPage(c, reactors)
+}
+
+func (h *ReactionController) React(c *gin.Context) {
+	postID, commentID, ok := targetParams(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.service.React(postID, commentID, viewerID(c), c.Param("emoji"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+func (h *ReactionController) Unreact(c *gin.Context) {
+	postID, commentID, ok := targetParams(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.service.Unreact(postID, commentID, viewerID(c), c.Param("emoji"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// targetParams разбирает идентификаторы поста и, для маршрутов
+// комментариев, комментария. Для маршрутов поста commentID равен 0.
+func targetParams(c *gin.Context) (postID, commentID int64, ok bool) {
+	if c.Param("comment_id") != "" {
+		return commentParams(c)
+	}
+
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return 0, 0, false
+	}
+	return postID, 0, true
+}
+
+// viewerID возвращает идентификатор аутентифицированного пользователя
+// или 0 для анонимного запроса
+func viewerID(c *gin.Context) int64 {
+	userID, _ := c.Get("user_id")
+	id, _ := userID.(int64)
+	return id
+}
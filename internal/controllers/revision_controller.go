@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fire9900/golang-forum/internal/httputil"
+	"github.com/fire9900/golang-forum/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RevisionController struct {
+	service *service.RevisionService
+}
+
+func NewRevisionController(service *service.RevisionService) *RevisionController {
+	return &RevisionController{service: service}
+}
+
+func (h *RevisionController) GetHistory(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	history, err := h.service.GetHistory(postID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+func (h *RevisionController) Get(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	number, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rev parameter"})
+		return
+	}
+
+	revision, err := h.service.Get(postID, number)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+func (h *RevisionController) Diff(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	details := make(map[string]string)
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		details["from"] = "must be a revision number"
+	}
+	to, err := strconv.Atoi(c.Query("to"))
+	if err != nil {
+		details["to"] = "must be a revision number"
+	}
+	if len(details) > 0 {
+		c.JSON(http.StatusBadRequest, httputil.NewValidationError(details))
+		return
+	}
+
+	result, err := h.service.Diff(postID, from, to, c.Query("mode"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fire9900/golang-forum/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchController struct {
+	service *service.SearchService
+	users   *service.UserService
+}
+
+func NewSearchController(service *service.SearchService, users *service.UserService) *SearchController {
+	return &SearchController{service: service, users: users}
+}
+
+func (h *SearchController) Search(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+
+	results, err := h.service.Search(c.Query("q"), page, perPage)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !attachAuthors(c, func() error { return h.users.AttachSearchAuthors(results.Hits) }) {
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SubscriptionController struct {
+	service *service.SubscriptionService
+	users   *service.UserService
+}
+
+func NewSubscriptionController(service *service.SubscriptionService, users *service.UserService) *SubscriptionController {
+	return &SubscriptionController{service: service, users: users}
+}
+
+func (h *SubscriptionController) Get(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	subscription, err := h.service.Get(viewerID(c), postID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+func (h *SubscriptionController) Set(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	var req model.SubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription, err := h.service.Set(viewerID(c), postID, req.Level)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+func (h *SubscriptionController) Delete(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	if err := h.service.Delete(viewerID(c), postID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetMine возвращает подписки пользователя, при необходимости только
+// указанного в параметре level уровня
+func (h *SubscriptionController) GetMine(c *gin.Context) {
+	req, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	subscriptions, err := h.service.GetByUser(viewerID(c), c.Query("level"), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !attachAuthors(c, func() error { return h.users.AttachSubscriptionAuthors(subscriptions.Items) }) {
+		return
+	}
+
+	respondPage(c, subscriptions)
+}
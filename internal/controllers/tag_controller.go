@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/fire9900/golang-forum/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagController struct {
+	service *service.TagService
+}
+
+func NewTagController(service *service.TagService) *TagController {
+	return &TagController{service: service}
+}
+
+func (h *TagController) GetAll(c *gin.Context) {
+	tags, err := h.service.GetAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
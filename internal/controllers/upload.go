@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead — запас на служебные части multipart-запроса сверх
+// размера самого файла
+const multipartOverhead = 1 << 20
+
+// formFile возвращает файл из поля file multipart-формы, ограничивая тело
+// запроса файлом размером maxSize. Если запрос больше, отвечает ошибкой
+// tooLarge. При ошибке отправляет ответ и возвращает ok = false.
+func formFile(c *gin.Context, maxSize int64, tooLarge error) (header *multipart.FileHeader, ok bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondError(c, tooLarge)
+			return nil, false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return nil, false
+	}
+	return header, true
+}
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fire9900/golang-forum/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UserController struct {
+	service *service.UserService
+}
+
+func NewUserController(service *service.UserService) *UserController {
+	return &UserController{service: service}
+}
+
+// GetByID возвращает профиль пользователя
+func (h *UserController) GetByID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	user, err := h.service.GetProfile(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// GetByUsername возвращает профиль пользователя по имени
+func (h *UserController) GetByUsername(c *gin.Context) {
+	user, err := h.service.GetProfileByUsername(c.Param("name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
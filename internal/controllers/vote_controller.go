@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VoteController struct {
+	service *service.VoteService
+}
+
+func NewVoteController(service *service.VoteService) *VoteController {
+	return &VoteController{service: service}
+}
+
+func (h *VoteController) VotePost(c *gin.Context) {
+	var vote model.Vote
+	if err := c.ShouldBindJSON(&vote); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.votePost(c, vote.Value)
+}
+
+func (h *VoteController) RetractPostVote(c *gin.Context) {
+	h.votePost(c, 0)
+}
+
+func (h *VoteController) VoteComment(c *gin.Context) {
+	var vote model.Vote
+	if err := c.ShouldBindJSON(&vote); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.voteComment(c, vote.Value)
+}
+
+func (h *VoteController) RetractCommentVote(c *gin.Context) {
+	h.voteComment(c, 0)
+}
+
+func (h *VoteController) votePost(c *gin.Context, value int) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id parameter"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	result, err := h.service.VotePost(postID, userID.(int64), value)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *VoteController) voteComment(c *gin.Context, value int) {
+	postID, commentID, ok := commentParams(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	result, err := h.service.VoteComment(postID, commentID, userID.(int64), value)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
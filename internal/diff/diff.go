@@ -0,0 +1,262 @@
+// Package diff вычисляет построчные и пословные различия между текстами
+// алгоритмом Майерса.
+package diff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Виды операций в результате сравнения
+const (
+	Equal  = "equal"
+	Insert = "insert"
+	Delete = "delete"
+)
+
+const (
+	// maxEditDistance ограничивает объем работы и памяти алгоритма: при
+	// большем числе правок тексты считаются полностью различающимися.
+	// Память под историю поиска растет как квадрат этого значения.
+	maxEditDistance = 1000
+	// maxTokens ограничивает число строк или слов в каждом из текстов;
+	// более длинные тексты считаются полностью различающимися без поиска
+	maxTokens = 20000
+)
+
+// Op описывает фрагмент текста, который совпадает, добавлен или удален
+type Op struct {
+	Kind string `json:"op"`
+	Text string `json:"text"`
+}
+
+// Words сравнивает тексты по словам. Пробельные символы считаются
+// отдельными токенами, поэтому конкатенация Text всех операций Equal и
+// Delete дает исходный текст a, а Equal и Insert — текст b.
+func Words(a, b string) []Op {
+	return merge(compare(splitWords(a), splitWords(b)))
+}
+
+// Lines сравнивает тексты построчно
+func Lines(a, b string) []Op {
+	return compare(splitLines(a), splitLines(b))
+}
+
+// Unified возвращает построчную разницу в формате unified diff с context
+// строками контекста вокруг каждого изменения
+func Unified(a, b, fromName, toName string, context int) string {
+	ops := Lines(a, b)
+	if !slices.ContainsFunc(ops, func(op Op) bool { return op.Kind != Equal }) {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+
+	// Номера строк (с нуля) для каждой операции в текстах a и b
+	aLine, bLine := make([]int, len(ops)), make([]int, len(ops))
+	for i, x, y := 0, 0, 0; i < len(ops); i++ {
+		aLine[i], bLine[i] = x, y
+		if ops[i].Kind != Insert {
+			x++
+		}
+		if ops[i].Kind != Delete {
+			y++
+		}
+	}
+
+	for i := 0; i < len(ops); {
+		if ops[i].Kind == Equal {
+			i++
+			continue
+		}
+
+		// Границы блока: изменения, разделенные не более чем 2*context
+		// совпадающими строками, попадают в один блок
+		start := max(i-context, 0)
+		end := i
+		for end < len(ops) {
+			if ops[end].Kind != Equal {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].Kind == Equal {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				end = min(end+context, len(ops))
+				break
+			}
+			end = run
+		}
+
+		var aCount, bCount int
+		for _, op := range ops[start:end] {
+			if op.Kind != Insert {
+				aCount++
+			}
+			if op.Kind != Delete {
+				bCount++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(aLine[start], aCount), hunkRange(bLine[start], bCount))
+
+		for _, op := range ops[start:end] {
+			prefix := " "
+			switch op.Kind {
+			case Insert:
+				prefix = "+"
+			case Delete:
+				prefix = "-"
+			}
+			sb.WriteString(prefix + op.Text + "\n")
+		}
+		i = end
+	}
+
+	return sb.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// splitWords разбивает текст на слова и промежутки между ними
+func splitWords(s string) []string {
+	var (
+		tokens []string
+		start  int
+	)
+	runes := []rune(s)
+	for i := 1; i <= len(runes); i++ {
+		if i == len(runes) || unicode.IsSpace(runes[i]) != unicode.IsSpace(runes[i-1]) {
+			tokens = append(tokens, string(runes[start:i]))
+			start = i
+		}
+	}
+	return tokens
+}
+
+// merge объединяет соседние операции одного вида
+func merge(ops []Op) []Op {
+	merged := make([]Op, 0, len(ops))
+	for i := 0; i < len(ops); {
+		j := i + 1
+		for j < len(ops) && ops[j].Kind == ops[i].Kind {
+			j++
+		}
+
+		var sb strings.Builder
+		for _, op := range ops[i:j] {
+			sb.WriteString(op.Text)
+		}
+		merged = append(merged, Op{Kind: ops[i].Kind, Text: sb.String()})
+		i = j
+	}
+	return merged
+}
+
+// compare находит кратчайший сценарий правок, превращающий a в b
+func compare(a, b []string) []Op {
+	n, m := len(a), len(b)
+	if n > maxTokens || m > maxTokens {
+		return replaceAll(a, b)
+	}
+
+	limit := min(n+m, maxEditDistance)
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+
+	// На шаге d сохраняются только диагонали -d-1..d+1, которые читает
+	// обратный проход
+	var trace [][]int
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, slices.Clone(v[offset-d-1:offset+d+2]))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b)
+			}
+		}
+	}
+
+	// Слишком много различий: считаем, что текст заменен целиком
+	return replaceAll(a, b)
+}
+
+// replaceAll описывает замену текста a текстом b целиком
+func replaceAll(a, b []string) []Op {
+	ops := make([]Op, 0, len(a)+len(b))
+	for _, s := range a {
+		ops = append(ops, Op{Kind: Delete, Text: s})
+	}
+	for _, s := range b {
+		ops = append(ops, Op{Kind: Insert, Text: s})
+	}
+	return ops
+}
+
+func backtrack(trace [][]int, a, b []string) []Op {
+	var ops []Op
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		// Диагональ k хранится в trace[d] по индексу k+d+1
+		offset := d + 1
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, Op{Kind: Equal, Text: a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, Op{Kind: Insert, Text: b[y-1]})
+			} else {
+				ops = append(ops, Op{Kind: Delete, Text: a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	slices.Reverse(ops)
+	return ops
+}
@@ -0,0 +1,35 @@
+package httputil
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetLinkHeader устанавливает заголовок Link (RFC 5988) со ссылками на
+// следующую и предыдущую страницы списка
+func SetLinkHeader(ctx *gin.Context, nextCursor, prevCursor string) {
+	var links []string
+	if nextCursor != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(ctx, "after", nextCursor)))
+	}
+	if prevCursor != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(ctx, "before", prevCursor)))
+	}
+
+	if len(links) > 0 {
+		ctx.Header("Link", strings.Join(links, ", "))
+	}
+}
+
+// pageURL возвращает адрес текущего запроса с заменой курсора страницы
+func pageURL(ctx *gin.Context, param, cursor string) string {
+	u := *ctx.Request.URL
+	query := u.Query()
+	query.Del("after")
+	query.Del("before")
+	query.Set(param, cursor)
+	u.RawQuery = query.Encode()
+	return u.RequestURI()
+}
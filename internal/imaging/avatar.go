@@ -0,0 +1,31 @@
+package imaging
+
+import "image"
+
+// Avatar обрезает изображение до квадрата по центру и возвращает его копии
+// со сторонами sides в том же порядке. Изображения меньше запрошенной
+// стороны не увеличиваются.
+func Avatar(data []byte, sides []int) ([]*Image, error) {
+	img, format, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	side := min(w, h)
+	x, y := (w-side)/2, (h-side)/2
+	square := img.SubImage(image.Rect(x, y, x+side, y+side)).(*image.RGBA)
+
+	encode := encodePNG
+	if format == "jpeg" {
+		encode = encodeJPEG
+	}
+
+	images := make([]*Image, len(sides))
+	for i, side := range sides {
+		if images[i], err = encode(fit(square, side)); err != nil {
+			return nil, err
+		}
+	}
+	return images, nil
+}
@@ -0,0 +1,72 @@
+package imaging
+
+import (
+	"crypto/sha256"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// identiconGrid — число клеток узора по каждой стороне
+const identiconGrid = 5
+
+// identiconBackground — цвет фона узора
+var identiconBackground = color.RGBA{R: 0xf0, G: 0xf0, B: 0xf0, A: 0xff}
+
+// Identicon рисует квадратный узор со стороной side, однозначно
+// определяемый seed. Узор симметричен относительно вертикальной оси.
+func Identicon(seed []byte, side int) (*Image, error) {
+	hash := sha256.Sum256(seed)
+	fg := hslColor(float64(hash[0])/255*360, 0.5, 0.55)
+
+	img := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(img, img.Rect, &image.Uniform{C: identiconBackground}, image.Point{}, draw.Src)
+
+	// Поля вокруг узора равны половине клетки
+	cell := side / (identiconGrid + 1)
+	margin := (side - cell*identiconGrid) / 2
+	for row := 0; row < identiconGrid; row++ {
+		for col := 0; col < (identiconGrid+1)/2; col++ {
+			bit := row*identiconGrid + col
+			if hash[1+bit/8]>>(bit%8)&1 == 0 {
+				continue
+			}
+			for _, c := range []int{col, identiconGrid - 1 - col} {
+				x, y := margin+c*cell, margin+row*cell
+				draw.Draw(img, image.Rect(x, y, x+cell, y+cell), &image.Uniform{C: fg}, image.Point{}, draw.Src)
+			}
+		}
+	}
+	return encodePNG(img)
+}
+
+// hslColor переводит цвет из HSL в RGB. Тон h задается в градусах,
+// насыщенность s и яркость l — от 0 до 1.
+func hslColor(h, s, l float64) color.RGBA {
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	return color.RGBA{
+		R: uint8(math.Round((r + m) * 255)),
+		G: uint8(math.Round((g + m) * 255)),
+		B: uint8(math.Round((b + m) * 255)),
+		A: 0xff,
+	}
+}
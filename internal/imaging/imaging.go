@@ -0,0 +1,148 @@
+// Package imaging готовит загруженные изображения к показу: учитывает
+// ориентацию из EXIF, удаляет метаданные и создает уменьшенные копии и
+// аватары. Поддерживаются форматы JPEG, PNG и GIF. Для пользователей без
+// аватара рисуются узоры-идентиконы.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+)
+
+// MaxPixels ограничивает размер обрабатываемого изображения, защищая от
+// файлов, которые при распаковке занимают чрезмерный объем памяти
+const MaxPixels = 40_000_000
+
+// jpegQuality — качество сжатия создаваемых изображений JPEG
+const jpegQuality = 85
+
+var (
+	ErrUnsupported = errors.New("unsupported image format")
+	ErrTooLarge    = errors.New("image dimensions are too large")
+)
+
+// Size описывает уменьшенную копию изображения
+type Size struct {
+	Name    string
+	MaxSide int
+}
+
+// Sizes перечисляет создаваемые уменьшенные копии
+var Sizes = []Size{
+	{Name: "thumb", MaxSide: 320},
+	{Name: "medium", MaxSide: 1280},
+}
+
+// Image содержит закодированное изображение
+type Image struct {
+	ContentType string
+	Data        []byte
+	Width       int
+	Height      int
+}
+
+// Result содержит результат обработки изображения
+type Result struct {
+	// Original — полноразмерная копия без метаданных. JPEG и PNG всегда
+	// перекодируются; для GIF Original равна nil, и исходный файл
+	// отдается как есть, чтобы сохранить анимацию.
+	Original *Image
+	// Variants — уменьшенные копии по именам из Sizes
+	Variants map[string]*Image
+	Width    int
+	Height   int
+}
+
+// Process обрабатывает изображение JPEG, PNG или GIF. Анимация GIF
+// сохраняется только в исходном файле, уменьшенные копии строятся по
+// первому кадру.
+func Process(data []byte) (*Result, error) {
+	img, format, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// JPEG остается JPEG, остальные форматы кодируются в PNG
+	encode := encodePNG
+	if format == "jpeg" {
+		encode = encodeJPEG
+	}
+
+	result := &Result{
+		Variants: make(map[string]*Image, len(Sizes)),
+		Width:    img.Rect.Dx(),
+		Height:   img.Rect.Dy(),
+	}
+
+	// GIF не содержит EXIF, и его перекодирование потеряло бы анимацию
+	if format != "gif" {
+		if result.Original, err = encode(img); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, size := range Sizes {
+		variant, err := encode(fit(img, size.MaxSide))
+		if err != nil {
+			return nil, err
+		}
+		result.Variants[size.Name] = variant
+	}
+	return result, nil
+}
+
+// decode декодирует изображение JPEG, PNG или первый кадр GIF и
+// поворачивает его согласно ориентации из EXIF
+func decode(data []byte) (*image.RGBA, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrUnsupported
+	}
+	if config.Width*config.Height > MaxPixels {
+		return nil, "", ErrTooLarge
+	}
+
+	var decoded image.Image
+	switch format {
+	case "jpeg":
+		decoded, err = jpeg.Decode(bytes.NewReader(data))
+	case "png":
+		decoded, err = png.Decode(bytes.NewReader(data))
+	case "gif":
+		decoded, err = gif.Decode(bytes.NewReader(data))
+	default:
+		return nil, "", ErrUnsupported
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("error decoding %s: %w", format, err)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, decoded.Bounds().Dx(), decoded.Bounds().Dy()))
+	draw.Draw(img, img.Rect, decoded, decoded.Bounds().Min, draw.Src)
+	if format == "jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+	return img, format, nil
+}
+
+func encodeJPEG(img *image.RGBA) (*Image, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+	return &Image{ContentType: "image/jpeg", Data: buf.Bytes(), Width: img.Rect.Dx(), Height: img.Rect.Dy()}, nil
+}
+
+func encodePNG(img *image.RGBA) (*Image, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return &Image{ContentType: "image/png", Data: buf.Bytes(), Width: img.Rect.Dx(), Height: img.Rect.Dy()}, nil
+}
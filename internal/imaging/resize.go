@@ -0,0 +1,99 @@
+package imaging
+
+import (
+	"image"
+	"math"
+)
+
+// fit уменьшает изображение так, чтобы большая сторона не превышала
+// maxSide, сохраняя пропорции. Меньшие изображения возвращаются без
+// изменений.
+func fit(src *image.RGBA, maxSide int) *image.RGBA {
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	if w <= maxSide && h <= maxSide {
+		return src
+	}
+
+	dw, dh := maxSide, maxSide
+	if w > h {
+		dh = max(1, int(math.Round(float64(h)*float64(maxSide)/float64(w))))
+	} else {
+		dw = max(1, int(math.Round(float64(w)*float64(maxSide)/float64(h))))
+	}
+
+	return resampleY(resampleX(src, dw), dh)
+}
+
+// contribution задает вклад исходных пикселей в один пиксель результата
+type contribution struct {
+	start   int
+	weights []float32
+}
+
+// contributions вычисляет для каждого пикселя результата доли покрываемых
+// им исходных пикселей (усреднение по площади)
+func contributions(srcSize, dstSize int) []contribution {
+	scale := float64(srcSize) / float64(dstSize)
+	result := make([]contribution, dstSize)
+	for i := range result {
+		from := float64(i) * scale
+		to := from + scale
+		start := int(from)
+		end := min(int(math.Ceil(to)), srcSize)
+
+		weights := make([]float32, 0, end-start)
+		for j := start; j < end; j++ {
+			overlap := math.Min(to, float64(j+1)) - math.Max(from, float64(j))
+			weights = append(weights, float32(overlap/scale))
+		}
+		result[i] = contribution{start: start, weights: weights}
+	}
+	return result
+}
+
+func resampleX(src *image.RGBA, dw int) *image.RGBA {
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, dw, h))
+	contribs := contributions(w, dw)
+
+	for y := 0; y < h; y++ {
+		row := src.PixOffset(src.Rect.Min.X, src.Rect.Min.Y+y)
+		for x, c := range contribs {
+			var acc [4]float32
+			for k, weight := range c.weights {
+				p := row + (c.start+k)*4
+				for ch := 0; ch < 4; ch++ {
+					acc[ch] += float32(src.Pix[p+ch]) * weight
+				}
+			}
+			setPixel(dst.Pix[dst.PixOffset(x, y):], acc)
+		}
+	}
+	return dst
+}
+
+func resampleY(src *image.RGBA, dh int) *image.RGBA {
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, dh))
+	contribs := contributions(h, dh)
+
+	for y, c := range contribs {
+		for x := 0; x < w; x++ {
+			var acc [4]float32
+			for k, weight := range c.weights {
+				p := src.PixOffset(x, c.start+k)
+				for ch := 0; ch < 4; ch++ {
+					acc[ch] += float32(src.Pix[p+ch]) * weight
+				}
+			}
+			setPixel(dst.Pix[dst.PixOffset(x, y):], acc)
+		}
+	}
+	return dst
+}
+
+func setPixel(pix []uint8, acc [4]float32) {
+	for ch := 0; ch < 4; ch++ {
+		pix[ch] = uint8(min(255, max(0, acc[ch]+0.5)))
+	}
+}
@@ -0,0 +1,68 @@
+// Package markdown преобразует текст постов и комментариев в безопасный
+// HTML. Поддерживаются CommonMark, таблицы, зачеркивание, автоссылки и
+// подсветка синтаксиса в блоках кода.
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/extension"
+)
+
+// converter разбирает Markdown. Встроенный HTML не выводится, а подсветка
+// оформляется CSS-классами, чтобы не разрешать атрибут style.
+var converter = goldmark.New(
+	goldmark.WithExtensions(
+		extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)),
+		extension.Strikethrough,
+		extension.Linkify,
+		highlighting.NewHighlighting(
+			highlighting.WithFormatOptions(chromahtml.WithClasses(true)),
+		),
+	),
+)
+
+// attachmentImage совпадает с адресами вложений и их уменьшенных копий
+var attachmentImage = regexp.MustCompile(`^/api/attachments/[0-9]+(\?size=[a-z]+)?$`)
+
+// policy пропускает только разметку, которую порождает converter
+var policy = newPolicy()
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+
+	p.AllowElements(
+		"p", "br", "hr", "blockquote", "h1", "h2", "h3", "h4", "h5", "h6",
+		"ul", "ol", "li", "strong", "em", "del", "code", "pre",
+		"table", "thead", "tbody", "tr", "th", "td",
+	)
+	p.AllowAttrs("start").Matching(bluemonday.Integer).OnElements("ol")
+	p.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|center|right)$`)).OnElements("th", "td")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^[a-z0-9]+( [a-z0-9]+)*$`)).OnElements("pre", "code", "span")
+
+	p.AllowStandardURLs()
+	p.AllowURLSchemes("http", "https", "mailto")
+	p.AllowAttrs("href", "title").OnElements("a")
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	// Изображения разрешены только из вложений форума, чтобы посты не
+	// загружали сторонние ресурсы и не сообщали внешним сайтам о читателях
+	p.AllowAttrs("src").Matching(attachmentImage).OnElements("img")
+	p.AllowAttrs("alt", "title").OnElements("img")
+
+	return p
+}
+
+// Render возвращает очищенный HTML для текста source
+func Render(source string) (string, error) {
+	var buf bytes.Buffer
+	if err := converter.Convert([]byte(source), &buf); err != nil {
+		return "", err
+	}
+	return string(policy.SanitizeBytes(buf.Bytes())), nil
+}
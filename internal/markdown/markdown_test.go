@@ -0,0 +1,109 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "script tag",
+			source:   "hello <script>alert(1)</script>",
+			contains: []string{"hello"},
+			excludes: []string{"<script", "alert(1)</script>"},
+		},
+		{
+			name:     "javascript link",
+			source:   "[click](javascript:alert(1))",
+			contains: []string{"click"},
+			excludes: []string{"javascript:", "href"},
+		},
+		{
+			name:     "javascript link with entity",
+			source:   "[click](jav&#x61;script:alert(1))",
+			excludes: []string{"javascript", "href"},
+		},
+		{
+			name:     "event handler in raw html",
+			source:   `<img src="/api/attachments/1" onerror="alert(1)">`,
+			excludes: []string{"onerror", "alert"},
+		},
+		{
+			name:     "event handler in markdown image title",
+			source:   `![x](/api/attachments/1 "t\" onload=\"alert(1)")`,
+			excludes: []string{`onload="`},
+		},
+		{
+			name:     "raw html block",
+			source:   "<div style=\"color:red\"><iframe src=\"https://example.com\"></iframe></div>",
+			excludes: []string{"<div", "<iframe", "style="},
+		},
+		{
+			name:     "inline raw html",
+			source:   "text <b onclick=\"x()\">bold</b>",
+			excludes: []string{"<b", "onclick"},
+		},
+		{
+			name:     "external image",
+			source:   "![pixel](https://tracker.example.com/p.gif)",
+			contains: []string{"<img"},
+			excludes: []string{"tracker.example.com", "src="},
+		},
+		{
+			name:     "attachment image",
+			source:   "![photo](/api/attachments/42?size=medium)",
+			contains: []string{`<img src="/api/attachments/42?size=medium" alt="photo"`},
+		},
+		{
+			name:     "external link",
+			source:   "[site](https://example.com)",
+			contains: []string{`href="https://example.com"`, `rel="nofollow noopener"`, `target="_blank"`},
+		},
+		{
+			name:     "highlighted code keeps chroma classes",
+			source:   "```go\nfunc main() {}\n```",
+			contains: []string{`<pre class="chroma">`, `<span class="kd">func</span>`, `class="nf"`},
+			excludes: []string{"style="},
+		},
+		{
+			name:     "class with unexpected characters",
+			source:   "<span class=\"x;y\">a</span>",
+			excludes: []string{"class="},
+		},
+		{
+			name:     "table alignment",
+			source:   "| a | b |\n|:-|-:|\n| 1 | 2 |",
+			contains: []string{`<th align="left">a</th>`, `<td align="right">2</td>`},
+		},
+		{
+			name:     "strikethrough",
+			source:   "~~gone~~",
+			contains: []string{"<del>gone</del>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html, err := Render(tt.source)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(html, want) {
+					t.Errorf("output does not contain %q:\n%s", want, html)
+				}
+			}
+			for _, bad := range tt.excludes {
+				if strings.Contains(html, bad) {
+					t.Errorf("output contains %q:\n%s", bad, html)
+				}
+			}
+		})
+	}
+}
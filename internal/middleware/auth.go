@@ -15,33 +15,56 @@ const (
 
 func AuthMiddleware(authClient *auth.GrpcAuthClient) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		header := c.GetHeader(authorizationHeader)
-		if header == "" {
+		if c.GetHeader(authorizationHeader) == "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Пустой заголовок авторизации"})
 			return
 		}
 
-		headerParts := strings.Split(header, " ")
-		if len(headerParts) != 2 || headerParts[0] != "Bearer" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Неверный формат токена"})
-			return
+		if authenticate(c, authClient) {
+			c.Next()
 		}
+	}
+}
 
-		if len(headerParts[1]) == 0 {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Токен пуст"})
+// OptionalAuthMiddleware аутентифицирует пользователя, если запрос содержит
+// заголовок авторизации, и пропускает анонимные запросы без изменений.
+// Используется в публичных маршрутах, ответ которых зависит от пользователя.
+func OptionalAuthMiddleware(authClient *auth.GrpcAuthClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader(authorizationHeader) == "" {
+			c.Next()
 			return
 		}
 
-		userID, err := authClient.ValidateToken(headerParts[1])
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "Неверный access токен",
-				"code":  "invalid_access_token",
-			})
-			return
+		if authenticate(c, authClient) {
+			c.Next()
 		}
+	}
+}
+
+// authenticate проверяет токен из заголовка авторизации и сохраняет
+// идентификатор пользователя в контексте. При ошибке прерывает запрос.
+func authenticate(c *gin.Context, authClient *auth.GrpcAuthClient) bool {
+	headerParts := strings.Split(c.GetHeader(authorizationHeader), " ")
+	if len(headerParts) != 2 || headerParts[0] != "Bearer" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Неверный формат токена"})
+		return false
+	}
 
-		c.Set(userCtx, userID)
-		c.Next()
+	if len(headerParts[1]) == 0 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Токен пуст"})
+		return false
 	}
+
+	userID, err := authClient.ValidateToken(headerParts[1])
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": "Неверный access токен",
+			"code":  "invalid_access_token",
+		})
+		return false
+	}
+
+	c.Set(userCtx, userID)
+	return true
 }
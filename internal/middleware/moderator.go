@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const moderatorCtx = "is_moderator"
+
+// ModeratorMiddleware отмечает в контексте, является ли аутентифицированный
+// пользователь модератором. Должен выполняться после AuthMiddleware.
+func ModeratorMiddleware(moderatorIDs []int64) gin.HandlerFunc {
+	moderators := make(map[int64]bool, len(moderatorIDs))
+	for _, id := range moderatorIDs {
+		moderators[id] = true
+	}
+
+	return func(c *gin.Context) {
+		userID, _ := c.Get(userCtx)
+		id, _ := userID.(int64)
+		c.Set(moderatorCtx, moderators[id])
+		c.Next()
+	}
+}
+
+// RequireModerator пропускает только запросы модераторов. Должен выполняться
+// после ModeratorMiddleware.
+func RequireModerator() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !c.GetBool(moderatorCtx) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Действие доступно только модераторам"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// Состояния обработки изображений во вложениях
+const (
+	// AttachmentProcessingNone — вложение не требует обработки
+	AttachmentProcessingNone       = "none"
+	AttachmentProcessingPending    = "pending"
+	AttachmentProcessingInProgress = "processing"
+	AttachmentProcessingDone       = "done"
+	AttachmentProcessingFailed     = "failed"
+)
+
+// Attachment описывает файл, прикрепленный к посту или комментарию.
+// Вложение, у которого нет ни поста, ни комментария, ожидает удаления.
+type Attachment struct {
+	ID          int64                `json:"id"`
+	UserID      int64                `json:"user_id"`
+	PostID      *int64               `json:"post_id"`
+	CommentID   *int64               `json:"comment_id,omitempty"`
+	StorageKey  string               `json:"-"`
+	Filename    string               `json:"filename"`
+	ContentType string               `json:"content_type"`
+	Size        int64                `json:"size"`
+	Processing  string               `json:"processing"`
+	Width       *int                 `json:"width,omitempty"`
+	Height      *int                 `json:"height,omitempty"`
+	Variants    []*AttachmentVariant `json:"variants,omitempty"`
+	URL         string               `json:"url"`
+	CreatedAt   time.Time            `json:"created_at"`
+}
+
+// AttachmentVariant описывает уменьшенную копию изображения
+type AttachmentVariant struct {
+	Name        string `json:"name"`
+	StorageKey  string `json:"-"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	URL         string `json:"url"`
+}
@@ -0,0 +1,9 @@
+package model
+
+// AvatarVariant описывает копию аватара пользователя с одной стороной
+type AvatarVariant struct {
+	Side        int
+	StorageKey  string
+	ContentType string
+	Size        int64
+}
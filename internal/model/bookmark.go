@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Bookmark представляет пост, сохраненный пользователем в закладки
+type Bookmark struct {
+	PostID    int64     `json:"post_id"`
+	Folder    string    `json:"folder"`
+	Note      string    `json:"note"`
+	Post      *Post     `json:"post,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BookmarkRequest содержит необязательные папку и личную заметку закладки
+type BookmarkRequest struct {
+	Folder string `json:"folder"`
+	Note   string `json:"note"`
+}
+
+// BookmarkFolder описывает папку закладок пользователя. Закладки без папки
+// относятся к папке с пустым именем.
+type BookmarkFolder struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
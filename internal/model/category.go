@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+type Category struct {
+	ID          int64       `json:"id"`
+	ParentID    *int64      `json:"parent_id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Position    int         `json:"position"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	Children    []*Category `json:"children,omitempty"`
+}
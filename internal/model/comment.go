@@ -3,10 +3,31 @@ package model
 import "time"
 
 type Comment struct {
-	ID        int64     `json:"id"`
-	Content   string    `json:"content"`
-	PostID    int64     `json:"post_id"`
-	AuthorID  int64     `json:"author_id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          int64     `json:"id"`
+	Content     string    `json:"content"`
+	ContentHTML string    `json:"content_html"`
+	PostID      int64     `json:"post_id"`
+	ParentID    *int64    `json:"parent_id"`
+	AuthorID    int64     `json:"author_id"`
+	Author      *Author   `json:"author,omitempty"`
+	Upvotes     int       `json:"upvotes"`
+	Downvotes   int       `json:"downvotes"`
+	Score       int       `json:"score"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CommentNode представляет комментарий в дереве обсуждения вместе с ответами
+type CommentNode struct {
+	Comment
+	Replies    []*CommentNode `json:"replies"`
+	ReplyCount int            `json:"reply_count"`
+	// MoreCursor позволяет догрузить оставшиеся ответы этой ветки
+	MoreCursor string `json:"more_cursor,omitempty"`
+}
+
+// CommentTree представляет уровень дерева комментариев поста
+type CommentTree struct {
+	Comments   []*CommentNode `json:"comments"`
+	NextCursor string         `json:"next_cursor,omitempty"`
 }
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Mention представляет упоминание пользователя в посте или комментарии
+type Mention struct {
+	ID        int64  `json:"id"`
+	PostID    int64  `json:"post_id"`
+	CommentID *int64 `json:"comment_id,omitempty"`
+	AuthorID  int64  `json:"author_id"`
+	Username  string `json:"username"`
+	// UserID не задан, если имя не удалось сопоставить с пользователем
+	UserID    *int64    `json:"user_id"`
+	Author    *Author   `json:"author,omitempty"`
+	Post      *Post     `json:"post,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// Poll представляет опрос, прикрепленный к посту. Число проголосовавших
+// и голоса вариантов заполняются, только если результаты видны.
+type Poll struct {
+	ID             int64         `json:"id"`
+	PostID         int64         `json:"post_id"`
+	Question       string        `json:"question"`
+	Multiple       bool          `json:"multiple"`
+	Anonymous      bool          `json:"anonymous"`
+	HideResults    bool          `json:"hide_results"`
+	ClosesAt       *time.Time    `json:"closes_at,omitempty"`
+	Closed         bool          `json:"closed"`
+	Options        []*PollOption `json:"options"`
+	Voters         *int          `json:"voters,omitempty"`
+	ResultsVisible bool          `json:"results_visible"`
+	MyVotes        []int64       `json:"my_votes,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// PollOption представляет вариант ответа опроса. VoterIDs заполняется
+// для открытых опросов с видимыми результатами.
+type PollOption struct {
+	ID       int64   `json:"id"`
+	Text     string  `json:"text"`
+	Votes    *int    `json:"votes,omitempty"`
+	VoterIDs []int64 `json:"voter_ids,omitempty"`
+}
+
+// PollRequest описывает создаваемый опрос
+type PollRequest struct {
+	Question    string     `json:"question" binding:"required"`
+	Options     []string   `json:"options" binding:"required"`
+	Multiple    bool       `json:"multiple"`
+	Anonymous   bool       `json:"anonymous"`
+	HideResults bool       `json:"hide_results"`
+	ClosesAt    *time.Time `json:"closes_at"`
+}
+
+// PollVote содержит выбранные пользователем варианты ответа
+type PollVote struct {
+	OptionIDs []int64 `json:"option_ids" binding:"required"`
+}
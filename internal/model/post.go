@@ -3,10 +3,73 @@ package model
 import "time"
 
 type Post struct {
-	ID        int64     `json:"id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	AuthorID  int64     `json:"author_id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID             int64            `json:"id"`
+	Title          string           `json:"title"`
+	Slug           string           `json:"slug"`
+	Content        string           `json:"content"`
+	ContentHTML    string           `json:"content_html"`
+	CategoryID     int64            `json:"category_id" binding:"required"`
+	Tags           []string         `json:"tags"`
+	AuthorID       int64            `json:"author_id"`
+	Author         *Author          `json:"author,omitempty"`
+	Status         string           `json:"status"`
+	PublishAt      *time.Time       `json:"publish_at,omitempty"`
+	Pinned         bool             `json:"pinned"`
+	PinScope       string           `json:"pin_scope,omitempty"`
+	Locked         bool             `json:"locked"`
+	Announcement   bool             `json:"announcement"`
+	CommentCount   int              `json:"comment_count"`
+	Upvotes        int              `json:"upvotes"`
+	Downvotes      int              `json:"downvotes"`
+	Score          int              `json:"score"`
+	Views          int              `json:"views"`
+	HotScore       float64          `json:"-"`
+	Controversy    float64          `json:"-"`
+	LastActivityAt time.Time        `json:"last_activity_at"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	DeletedAt      *time.Time       `json:"deleted_at,omitempty"`
+	DeletedBy      *int64           `json:"deleted_by,omitempty"`
+	Reactions      *ReactionSummary `json:"reactions,omitempty"`
+	Bookmarked     *bool            `json:"bookmarked,omitempty"`
+}
+
+// Статусы публикации поста
+const (
+	PostStatusDraft     = "draft"
+	PostStatusScheduled = "scheduled"
+	PostStatusPublished = "published"
+)
+
+// Области закрепления поста: во всех списках или только в своем разделе
+const (
+	PinScopeGlobal   = "global"
+	PinScopeCategory = "category"
+)
+
+// Режимы сортировки списка постов
+const (
+	PostSortNewest        = "newest"
+	PostSortOldest        = "oldest"
+	PostSortUpdated       = "updated"
+	PostSortComments      = "comments"
+	PostSortActive        = "active"
+	PostSortTop           = "top"
+	PostSortHot           = "hot"
+	PostSortControversial = "controversial"
+	PostSortViews         = "views"
+)
+
+// PostFilter задает условия выборки списка постов
+type PostFilter struct {
+	CategoryID    int64
+	Tags          []string
+	TagMode       string
+	AuthorID      int64
+	Title         string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	Sort          string
 }
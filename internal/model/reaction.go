@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// Типы объектов, к которым можно оставить реакцию
+const (
+	ReactionTargetPost    = "post"
+	ReactionTargetComment = "comment"
+)
+
+// ReactionCount содержит число реакций с одним эмодзи
+type ReactionCount struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+}
+
+// ReactionSummary содержит сводку реакций на объект и реакции текущего пользователя
+type ReactionSummary struct {
+	Counts []ReactionCount `json:"counts"`
+	Mine   []string        `json:"mine"`
+}
+
+// Reactor описывает пользователя, оставившего реакцию
+type Reactor struct {
+	UserID    int64     `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
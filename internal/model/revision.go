@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"github.com/fire9900/golang-forum/internal/diff"
+)
+
+// PostRevision представляет сохраненную версию заголовка и текста поста
+type PostRevision struct {
+	ID        int64     `json:"id"`
+	PostID    int64     `json:"post_id"`
+	Revision  int       `json:"revision"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	EditorID  int64     `json:"editor_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RevisionSummary описывает ревизию в истории правок без ее текста
+type RevisionSummary struct {
+	Revision  int       `json:"revision"`
+	EditorID  int64     `json:"editor_id"`
+	CreatedAt time.Time `json:"created_at"`
+	// Changed перечисляет поля, измененные по сравнению с предыдущей ревизией
+	Changed []string `json:"changed"`
+}
+
+// Режимы сравнения ревизий
+const (
+	DiffModeUnified = "unified"
+	DiffModeWords   = "words"
+)
+
+// RevisionDiff представляет различия между двумя ревизиями поста
+type RevisionDiff struct {
+	PostID  int64     `json:"post_id"`
+	From    int       `json:"from"`
+	To      int       `json:"to"`
+	Mode    string    `json:"mode"`
+	Title   []diff.Op `json:"title"`
+	Unified string    `json:"unified,omitempty"`
+	Content []diff.Op `json:"content,omitempty"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// Типы найденных объектов
+const (
+	SearchTypePost    = "post"
+	SearchTypeComment = "comment"
+)
+
+// SearchHit представляет один найденный пост или комментарий
+type SearchHit struct {
+	Type      string    `json:"type"`
+	ID        int64     `json:"id"`
+	PostID    int64     `json:"post_id"`
+	AuthorID  int64     `json:"author_id"`
+	Author    *Author   `json:"author,omitempty"`
+	Title     string    `json:"title"`
+	Content   string    `json:"-"`
+	Snippet   string    `json:"snippet"`
+	Score     float64   `json:"score"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SearchResults представляет страницу результатов поиска
+type SearchResults struct {
+	Query   string       `json:"query"`
+	Hits    []*SearchHit `json:"hits"`
+	Total   int          `json:"total"`
+	Page    int          `json:"page"`
+	PerPage int          `json:"per_page"`
+}
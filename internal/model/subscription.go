@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// Уровни подписки на тему
+const (
+	// SubscriptionWatching — уведомления о каждом новом комментарии
+	SubscriptionWatching = "watching"
+	// SubscriptionTracking — уведомления только об ответах пользователю
+	// и упоминаниях
+	SubscriptionTracking = "tracking"
+	// SubscriptionMuted — тема отслеживается без уведомлений, автоматическая
+	// подписка не меняет этот уровень
+	SubscriptionMuted = "muted"
+)
+
+// Subscription представляет подписку пользователя на тему
+type Subscription struct {
+	PostID    int64     `json:"post_id"`
+	Level     string    `json:"level"`
+	Post      *Post     `json:"post,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SubscriptionRequest задает уровень подписки
+type SubscriptionRequest struct {
+	Level string `json:"level" binding:"required"`
+}
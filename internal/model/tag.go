@@ -0,0 +1,13 @@
+package model
+
+type Tag struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Режимы фильтрации постов по нескольким тегам
+const (
+	TagModeAnd = "and"
+	TagModeOr  = "or"
+)
@@ -3,12 +3,23 @@ package model
 import "time"
 
 type User struct {
-	ID        int64     `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	Password  string    `json:"-"` // не отдаем пароль в JSON
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// Email не раскрывается в публичном профиле
+	Email        string    `json:"email,omitempty"`
+	Password     string    `json:"-"` // не отдаем пароль в JSON
+	PostCount    int       `json:"post_count"`
+	CommentCount int       `json:"comment_count"`
+	Avatar       string    `json:"avatar,omitempty"` // без загрузки отдается идентикон
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Author — краткие сведения об авторе поста или комментария
+type Author struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Avatar   string `json:"avatar,omitempty"`
 }
 
 type UserLogin struct {
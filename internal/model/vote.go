@@ -0,0 +1,14 @@
+package model
+
+// Vote представляет голос пользователя: 1 — за, -1 — против, 0 — голос отозван
+type Vote struct {
+	Value int `json:"value" binding:"oneof=-1 0 1"`
+}
+
+// VoteResult содержит счет объекта после голосования
+type VoteResult struct {
+	Upvotes   int `json:"upvotes"`
+	Downvotes int `json:"downvotes"`
+	Score     int `json:"score"`
+	MyVote    int `json:"my_vote"`
+}
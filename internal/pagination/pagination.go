@@ -0,0 +1,66 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Request описывает запрос страницы: не более Limit элементов после курсора
+// After или перед курсором Before
+type Request struct {
+	After  string
+	Before string
+	Limit  int
+}
+
+// Backward сообщает, что страница запрашивается в обратном направлении
+func (r Request) Backward() bool {
+	return r.Before != ""
+}
+
+// Cursor указывает позицию элемента в упорядоченном списке. Values содержит
+// значения ключей сортировки элемента, Sort — режим сортировки, для которого
+// курсор был выдан.
+type Cursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+// Encode возвращает непрозрачное строковое представление курсора
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// Decode разбирает курсор, выданный Encode, и проверяет, что он относится к
+// режиму сортировки sort и содержит keys значений
+func Decode(s, sort string, keys int) (Cursor, error) {
+	var c Cursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	if c.Sort != sort || len(c.Values) != keys {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// Page представляет страницу списка с курсорами соседних страниц
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
@@ -0,0 +1,311 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/fire9900/golang-forum/internal/model"
+)
+
+const attachmentColumns = `id, user_id, post_id, comment_id, storage_key, filename, content_type, size,
+	processing, width, height, created_at`
+
+const usedBytesQuery = "SELECT COALESCE(SUM(size), 0) FROM attachments WHERE user_id = ?"
+
+type AttachmentRepository struct {
+	db *sql.DB
+}
+
+func NewAttachmentRepository(db *sql.DB) *AttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+// Create сохраняет вложение, если вместе с ним файлы пользователя занимают
+// не больше quota байт. Возвращает false, если квота превышена.
+func (r *AttachmentRepository) Create(attachment *model.Attachment, quota int64) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// Блокировка пользователя исключает одновременные загрузки сверх квоты
+	if err := lockUser(tx, attachment.UserID); err != nil {
+		return false, err
+	}
+
+	var used int64
+	if err := tx.QueryRow(usedBytesQuery, attachment.UserID).Scan(&used); err != nil {
+		return false, err
+	}
+	if used+attachment.Size > quota {
+		return false, nil
+	}
+
+	query := `
+		INSERT INTO attachments (user_id, post_id, comment_id, storage_key, filename, content_type, size, processing)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	result, err := tx.Exec(query,
+		attachment.UserID,
+		attachment.PostID,
+		attachment.CommentID,
+		attachment.StorageKey,
+		attachment.Filename,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.Processing,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	if attachment.ID, err = result.LastInsertId(); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	created, err := r.GetByID(attachment.ID)
+	if err != nil {
+		return false, err
+	}
+	*attachment = *created
+	return true, nil
+}
+
+// GetByID возвращает вложение, прикрепленное к посту или комментарию
+func (r *AttachmentRepository) GetByID(id int64) (*model.Attachment, error) {
+	query := "SELECT " + attachmentColumns + " FROM attachments WHERE id = ? AND post_id IS NOT NULL"
+	attachment, err := scanAttachment(r.db.QueryRow(query, id))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadVariants([]*model.Attachment{attachment}); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// GetByPostID возвращает вложения самого поста без вложений его комментариев
+func (r *AttachmentRepository) GetByPostID(postID int64) ([]*model.Attachment, error) {
+	query := "SELECT " + attachmentColumns + " FROM attachments WHERE post_id = ? AND comment_id IS NULL ORDER BY id"
+	return r.getMany(query, postID)
+}
+
+func (r *AttachmentRepository) GetByCommentID(commentID int64) ([]*model.Attachment, error) {
+	query := "SELECT " + attachmentColumns + " FROM attachments WHERE comment_id = ? ORDER BY id"
+	return r.getMany(query, commentID)
+}
+
+// UsedBytes возвращает суммарный размер файлов пользователя
+func (r *AttachmentRepository) UsedBytes(userID int64) (int64, error) {
+	var used int64
+	err := r.db.QueryRow(usedBytesQuery, userID).Scan(&used)
+	return used, err
+}
+
+// Detach открепляет вложение от поста и комментария. Содержимое удаляется
+// позже вместе с остальными открепленными вложениями.
+func (r *AttachmentRepository) Detach(id int64) error {
+	return execAffectingRow(r.db, "UPDATE attachments SET post_id = NULL, comment_id = NULL WHERE id = ? AND post_id IS NOT NULL", id)
+}
+
+// GetDetached возвращает до limit открепленных вложений. Вложения, которые
+// обрабатываются менее staleAfter, пропускаются до окончания обработки.
+func (r *AttachmentRepository) GetDetached(staleAfter time.Duration, limit int) ([]*model.Attachment, error) {
+	query := "SELECT " + attachmentColumns + ` FROM attachments
+		WHERE post_id IS NULL
+			AND (processing <> 'processing' OR processing_started_at < NOW() - INTERVAL ? SECOND)
+		ORDER BY id LIMIT ?`
+	return r.getMany(query, int64(staleAfter.Seconds()), limit)
+}
+
+// Delete удаляет метаданные вложения и его уменьшенных копий
+func (r *AttachmentRepository) Delete(id int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM attachment_variants WHERE attachment_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM attachments WHERE id = ?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ClaimPending выбирает прикрепленное вложение, ожидающее обработки, и
+// отмечает начало обработки. Обработка, не завершившаяся за staleAfter,
+// считается прерванной и начинается заново. Строки, заблокированные другим
+// экземпляром приложения, пропускаются. Если вложений для обработки нет,
+// возвращает sql.ErrNoRows.
+func (r *AttachmentRepository) ClaimPending(staleAfter time.Duration) (*model.Attachment, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := "SELECT " + attachmentColumns + ` FROM attachments
+		WHERE post_id IS NOT NULL AND (processing = 'pending'
+			OR processing = 'processing' AND processing_started_at < NOW() - INTERVAL ? SECOND)
+		ORDER BY id LIMIT 1
+		FOR UPDATE SKIP LOCKED`
+	attachment, err := scanAttachment(tx.QueryRow(query, int64(staleAfter.Seconds())))
+	if err != nil {
+		return nil, err
+	}
+
+	query = "UPDATE attachments SET processing = 'processing', processing_started_at = NOW() WHERE id = ?"
+	if _, err := tx.Exec(query, attachment.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	attachment.Processing = model.AttachmentProcessingInProgress
+	return attachment, nil
+}
+
+// CompleteProcessing сохраняет результат обработки изображения. Если
+// original не nil, исходное содержимое заменяется очищенной копией.
+func (r *AttachmentRepository) CompleteProcessing(attachment *model.Attachment, original *model.AttachmentVariant, variants []*model.AttachmentVariant) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if original != nil {
+		query := "UPDATE attachments SET storage_key = ?, content_type = ?, size = ? WHERE id = ?"
+		if _, err := tx.Exec(query, original.StorageKey, original.ContentType, original.Size, attachment.ID); err != nil {
+			return err
+		}
+	}
+
+	query := "UPDATE attachments SET processing = 'done', width = ?, height = ? WHERE id = ?"
+	if _, err := tx.Exec(query, attachment.Width, attachment.Height, attachment.ID); err != nil {
+		return err
+	}
+
+	for _, variant := range variants {
+		query := `
+			REPLACE INTO attachment_variants (attachment_id, name, storage_key, content_type, size, width, height)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`
+		_, err := tx.Exec(query,
+			attachment.ID, variant.Name, variant.StorageKey, variant.ContentType, variant.Size, variant.Width, variant.Height)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FailProcessing отмечает, что изображение не удалось обработать
+func (r *AttachmentRepository) FailProcessing(id int64) error {
+	_, err := r.db.Exec("UPDATE attachments SET processing = 'failed' WHERE id = ?", id)
+	return err
+}
+
+func (r *AttachmentRepository) getMany(query string, args ...any) ([]*model.Attachment, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := []*model.Attachment{}
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadVariants(attachments); err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// loadVariants загружает уменьшенные копии изображений
+func (r *AttachmentRepository) loadVariants(attachments []*model.Attachment) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*model.Attachment, len(attachments))
+	args := make([]any, 0, len(attachments))
+	for _, attachment := range attachments {
+		byID[attachment.ID] = attachment
+		args = append(args, attachment.ID)
+	}
+
+	query := `SELECT attachment_id, name, storage_key, content_type, size, width, height
+		FROM attachment_variants WHERE attachment_id IN (` + placeholders(len(args)) + `)
+		ORDER BY attachment_id, width`
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var attachmentID int64
+		variant := &model.AttachmentVariant{}
+		err := rows.Scan(&attachmentID, &variant.Name, &variant.StorageKey, &variant.ContentType,
+			&variant.Size, &variant.Width, &variant.Height)
+		if err != nil {
+			return err
+		}
+		attachment := byID[attachmentID]
+		attachment.Variants = append(attachment.Variants, variant)
+	}
+	return rows.Err()
+}
+
+func scanAttachment(row rowScanner) (*model.Attachment, error) {
+	attachment := &model.Attachment{}
+	var postID, commentID, width, height sql.NullInt64
+	err := row.Scan(
+		&attachment.ID,
+		&attachment.UserID,
+		&postID,
+		&commentID,
+		&attachment.StorageKey,
+		&attachment.Filename,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.Processing,
+		&width,
+		&height,
+		&attachment.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if postID.Valid {
+		attachment.PostID = &postID.Int64
+	}
+	if commentID.Valid {
+		attachment.CommentID = &commentID.Int64
+	}
+	if width.Valid && height.Valid {
+		w, h := int(width.Int64), int(height.Int64)
+		attachment.Width, attachment.Height = &w, &h
+	}
+	return attachment, nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/fire9900/golang-forum/internal/model"
+)
+
+type AvatarRepository struct {
+	db *sql.DB
+}
+
+func NewAvatarRepository(db *sql.DB) *AvatarRepository {
+	return &AvatarRepository{db: db}
+}
+
+// Get возвращает копию аватара пользователя со стороной side
+func (r *AvatarRepository) Get(userID int64, side int) (*model.AvatarVariant, error) {
+	variant := &model.AvatarVariant{}
+	query := "SELECT side, storage_key, content_type, size FROM user_avatars WHERE user_id = ? AND side = ?"
+	err := r.db.QueryRow(query, userID, side).Scan(&variant.Side, &variant.StorageKey, &variant.ContentType, &variant.Size)
+	if err != nil {
+		return nil, err
+	}
+	return variant, nil
+}
+
+// Replace заменяет аватар пользователя новыми копиями и возвращает ключи
+// хранилища прежних копий
+func (r *AvatarRepository) Replace(userID int64, variants []*model.AvatarVariant) ([]string, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	keys, err := deleteAvatar(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, variant := range variants {
+		query := "INSERT INTO user_avatars (user_id, side, storage_key, content_type, size) VALUES (?, ?, ?, ?, ?)"
+		if _, err := tx.Exec(query, userID, variant.Side, variant.StorageKey, variant.ContentType, variant.Size); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Delete удаляет аватар пользователя и возвращает ключи хранилища его
+// копий. Если аватара нет, возвращает sql.ErrNoRows.
+func (r *AvatarRepository) Delete(userID int64) ([]string, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	keys, err := deleteAvatar(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// deleteAvatar удаляет строки аватара пользователя и возвращает ключи их
+// копий. Блокировка пользователя упорядочивает одновременные загрузки, в
+// том числе первую, когда блокировать в user_avatars еще нечего.
+func deleteAvatar(tx *sql.Tx, userID int64) ([]string, error) {
+	if err := lockUser(tx, userID); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query("SELECT storage_key FROM user_avatars WHERE user_id = ? FOR UPDATE", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []string{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM user_avatars WHERE user_id = ?", userID); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/pagination"
+)
+
+const bookmarkColumns = "b.post_id, b.folder, b.note, b.created_at, b.updated_at"
+
+// bookmarksOrder упорядочивает закладки от новых к старым
+var bookmarksOrder = keyset[*model.Bookmark]{name: "bookmarks", keys: []sortKey[*model.Bookmark]{
+	{column: "b.created_at", desc: true, value: func(b *model.Bookmark) string { return formatCursorTime(b.CreatedAt) }},
+	{column: "b.post_id", desc: true, value: func(b *model.Bookmark) string { return formatCursorInt(b.PostID) }},
+}}
+
+type BookmarkRepository struct {
+	db *sql.DB
+}
+
+func NewBookmarkRepository(db *sql.DB) *BookmarkRepository {
+	return &BookmarkRepository{db: db}
+}
+
+// Save добавляет пост в закладки пользователя или обновляет папку и заметку
+// существующей закладки
+func (r *BookmarkRepository) Save(userID int64, bookmark *model.Bookmark) error {
+	query := `
+		INSERT INTO bookmarks (user_id, post_id, folder, note) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE folder = VALUES(folder), note = VALUES(note)
+	`
+	if _, err := r.db.Exec(query, userID, bookmark.PostID, bookmark.Folder, bookmark.Note); err != nil {
+		return err
+	}
+
+	query = "SELECT " + bookmarkColumns + " FROM bookmarks b WHERE b.user_id = ? AND b.post_id = ?"
+	return scanBookmark(r.db.QueryRow(query, userID, bookmark.PostID), bookmark)
+}
+
+// Delete удаляет пост из закладок пользователя
+func (r *BookmarkRepository) Delete(userID, postID int64) error {
+	return execAffectingRow(r.db, "DELETE FROM bookmarks WHERE user_id = ? AND post_id = ?", userID, postID)
+}
+
+// GetByUser возвращает страницу закладок пользователя на опубликованные
+// посты. Если folder не nil, выбираются только закладки из этой папки.
+func (r *BookmarkRepository) GetByUser(userID int64, folder *string, req pagination.Request) (pagination.Page[*model.Bookmark], error) {
+	where := "WHERE b.user_id = ?"
+	args := []any{userID}
+	if folder != nil {
+		where += " AND b.folder = ?"
+		args = append(args, *folder)
+	}
+
+	condition, cursorArgs, err := bookmarksOrder.condition(req)
+	if err != nil {
+		return pagination.Page[*model.Bookmark]{}, err
+	}
+	if condition != "" {
+		where += " AND " + condition
+		args = append(args, cursorArgs...)
+	}
+
+	query := "SELECT " + bookmarkColumns + ` FROM bookmarks b
+		JOIN posts p ON p.id = b.post_id AND p.status = 'published' AND p.deleted_at IS NULL
+		` + where + " " + bookmarksOrder.orderBy(req) + " LIMIT ?"
+	args = append(args, req.Limit+1)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return pagination.Page[*model.Bookmark]{}, err
+	}
+	defer rows.Close()
+
+	bookmarks := []*model.Bookmark{}
+	for rows.Next() {
+		bookmark := &model.Bookmark{}
+		if err := scanBookmark(rows, bookmark); err != nil {
+			return pagination.Page[*model.Bookmark]{}, err
+		}
+		bookmarks = append(bookmarks, bookmark)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[*model.Bookmark]{}, err
+	}
+
+	return bookmarksOrder.page(bookmarks, req), nil
+}
+
+// GetFolders возвращает папки закладок пользователя с числом закладок в каждой
+func (r *BookmarkRepository) GetFolders(userID int64) ([]*model.BookmarkFolder, error) {
+	query := `
+		SELECT b.folder, COUNT(*)
+		FROM bookmarks b
+		JOIN posts p ON p.id = b.post_id AND p.status = 'published' AND p.deleted_at IS NULL
+		WHERE b.user_id = ?
+		GROUP BY b.folder
+		ORDER BY b.folder
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	folders := []*model.BookmarkFolder{}
+	for rows.Next() {
+		folder := &model.BookmarkFolder{}
+		if err := rows.Scan(&folder.Name, &folder.Count); err != nil {
+			return nil, err
+		}
+		folders = append(folders, folder)
+	}
+	return folders, rows.Err()
+}
+
+// Bookmarked возвращает множество постов из postIDs, находящихся
+// в закладках пользователя
+func (r *BookmarkRepository) Bookmarked(userID int64, postIDs []int64) (map[int64]bool, error) {
+	bookmarked := make(map[int64]bool)
+	if len(postIDs) == 0 {
+		return bookmarked, nil
+	}
+
+	args := []any{userID}
+	for _, id := range postIDs {
+		args = append(args, id)
+	}
+	query := "SELECT post_id FROM bookmarks WHERE user_id = ? AND post_id IN (" + placeholders(len(postIDs)) + ")"
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		bookmarked[id] = true
+	}
+	return bookmarked, rows.Err()
+}
+
+func scanBookmark(row rowScanner, bookmark *model.Bookmark) error {
+	return row.Scan(&bookmark.PostID, &bookmark.Folder, &bookmark.Note, &bookmark.CreatedAt, &bookmark.UpdatedAt)
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/fire9900/golang-forum/internal/model"
+)
+
+const categoryColumns = "id, parent_id, name, description, position, created_at, updated_at"
+
+type CategoryRepository struct {
+	db *sql.DB
+}
+
+func NewCategoryRepository(db *sql.DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+func scanCategory(row rowScanner) (*model.Category, error) {
+	category := &model.Category{}
+	var parentID sql.NullInt64
+	err := row.Scan(
+		&category.ID,
+		&parentID,
+		&category.Name,
+		&category.Description,
+		&category.Position,
+		&category.CreatedAt,
+		&category.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if parentID.Valid {
+		category.ParentID = &parentID.Int64
+	}
+	return category, nil
+}
+
+func (r *CategoryRepository) GetByID(id int64) (*model.Category, error) {
+	query := "SELECT " + categoryColumns + " FROM categories WHERE id = ?"
+	return scanCategory(r.db.QueryRow(query, id))
+}
+
+func (r *CategoryRepository) GetAll() ([]*model.Category, error) {
+	query := "SELECT " + categoryColumns + " FROM categories ORDER BY position ASC, name ASC"
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []*model.Category
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
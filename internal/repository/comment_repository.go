@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/fire9900/golang-forum/internal/model"
+)
+
+const commentColumns = "id, content, content_html, post_id, parent_id, author_id, upvotes, downvotes, score, created_at, updated_at"
+
+type CommentRepository struct {
+	db *sql.DB
+}
+
+func NewCommentRepository(db *sql.DB) *CommentRepository {
+	return &CommentRepository{db: db}
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanComment(row rowScanner) (*model.Comment, error) {
+	comment := &model.Comment{}
+	var (
+		contentHTML sql.NullString
+		parentID    sql.NullInt64
+	)
+	err := row.Scan(
+		&comment.ID,
+		&comment.Content,
+		&contentHTML,
+		&comment.PostID,
+		&parentID,
+		&comment.AuthorID,
+		&comment.Upvotes,
+		&comment.Downvotes,
+		&comment.Score,
+		&comment.CreatedAt,
+		&comment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	comment.ContentHTML = contentHTML.String
+	if parentID.Valid {
+		comment.ParentID = &parentID.Int64
+	}
+	return comment, nil
+}
+
+func scanComments(rows *sql.Rows) ([]*model.Comment, error) {
+	defer rows.Close()
+
+	comments := []*model.Comment{}
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+	return comments, rows.Err()
+}
+
+func (r *CommentRepository) Create(comment *model.Comment) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO comments (content, content_html, post_id, parent_id, author_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, NOW(), NOW())
+	`
+	result, err := tx.Exec(query, comment.Content, comment.ContentHTML, comment.PostID, comment.ParentID, comment.AuthorID)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	// updated_at поста не меняется: новый комментарий не является правкой поста
+	query = `
+		UPDATE posts
+		SET comment_count = comment_count + 1, last_activity_at = NOW(), updated_at = updated_at
+		WHERE id = ?
+	`
+	if _, err := tx.Exec(query, comment.PostID); err != nil {
+		return err
+	}
+
+	if err := subscribe(tx, comment.AuthorID, comment.PostID, model.SubscriptionTracking); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	comment.ID = id
+	return nil
+}
+
+func (r *CommentRepository) GetByID(id int64) (*model.Comment, error) {
+	query := "SELECT " + commentColumns + " FROM comments WHERE id = ?"
+	return scanComment(r.db.QueryRow(query, id))
+}
+
+func (r *CommentRepository) GetByPostID(postID int64, limit, offset int) ([]*model.Comment, error) {
+	query := "SELECT " + commentColumns + ` FROM comments WHERE post_id = ?
+		ORDER BY created_at ASC, id ASC LIMIT ? OFFSET ?`
+	rows, err := r.db.Query(query, postID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return scanComments(rows)
+}
+
+// GetAllByPostID возвращает все комментарии поста в хронологическом порядке
+func (r *CommentRepository) GetAllByPostID(postID int64) ([]*model.Comment, error) {
+	query := "SELECT " + commentColumns + ` FROM comments WHERE post_id = ?
+		ORDER BY created_at ASC, id ASC`
+	rows, err := r.db.Query(query, postID)
+	if err != nil {
+		return nil, err
+	}
+	return scanComments(rows)
+}
+
+func (r *CommentRepository) Update(comment *model.Comment) error {
+	query := `
+		UPDATE comments
+		SET content = ?, content_html = ?, updated_at = NOW()
+		WHERE id = ? AND post_id = ? AND author_id = ?
+	`
+	result, err := r.db.Exec(query, comment.Content, comment.ContentHTML, comment.ID, comment.PostID, comment.AuthorID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Delete удаляет комментарий, переподвешивая его ответы к родителю удаляемого комментария
+func (r *CommentRepository) Delete(id, postID, authorID int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var parentID sql.NullInt64
+	query := "SELECT parent_id FROM comments WHERE id = ? AND post_id = ? AND author_id = ? FOR UPDATE"
+	if err := tx.QueryRow(query, id, postID, authorID).Scan(&parentID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("UPDATE comments SET parent_id = ?, updated_at = updated_at WHERE parent_id = ?", parentID, id); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM comments WHERE id = ?", id); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM comment_votes WHERE comment_id = ?", id); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM reactions WHERE target_type = 'comment' AND target_id = ?", id); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM mentions WHERE comment_id = ?", id); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("UPDATE attachments SET post_id = NULL, comment_id = NULL WHERE comment_id = ?", id); err != nil {
+		return err
+	}
+
+	query = `
+		UPDATE posts
+		SET comment_count = GREATEST(comment_count - 1, 0), updated_at = updated_at
+		WHERE id = ?
+	`
+	if _, err := tx.Exec(query, postID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetUnrendered возвращает до limit комментариев без отрисованного HTML:
+// идентификаторы и исходный текст
+func (r *CommentRepository) GetUnrendered(limit int) (map[int64]string, error) {
+	return selectUnrendered(r.db, "SELECT id, content FROM comments WHERE content_html IS NULL LIMIT ?", limit)
+}
+
+// SetContentHTML сохраняет отрисованный HTML комментария, если он еще не
+// был сохранен вместе с новым текстом
+func (r *CommentRepository) SetContentHTML(id int64, html string) error {
+	query := "UPDATE comments SET content_html = ?, updated_at = updated_at WHERE id = ? AND content_html IS NULL"
+	_, err := r.db.Exec(query, html, id)
+	return err
+}
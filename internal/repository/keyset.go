@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fire9900/golang-forum/internal/pagination"
+)
+
+// cursorTimeLayout — формат значений TIMESTAMP в курсорах
+const cursorTimeLayout = "2006-01-02 15:04:05.999999"
+
+// sortKey описывает один столбец ключа сортировки при выборке по курсору
+type sortKey[T any] struct {
+	column string
+	desc   bool
+	value  func(T) string
+}
+
+// keyset задает порядок выборки. Последний ключ должен быть уникальным,
+// чтобы порядок элементов был однозначным.
+type keyset[T any] struct {
+	name string
+	keys []sortKey[T]
+}
+
+// condition строит условие выборки элементов, следующих за курсором
+// в направлении запроса
+func (k keyset[T]) condition(req pagination.Request) (string, []any, error) {
+	raw := req.After
+	if req.Backward() {
+		raw = req.Before
+	}
+	if raw == "" {
+		return "", nil, nil
+	}
+
+	cursor, err := pagination.Decode(raw, k.name, len(k.keys))
+	if err != nil {
+		return "", nil, err
+	}
+
+	// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ...
+	var (
+		alternatives []string
+		args         []any
+	)
+	for i, key := range k.keys {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, k.keys[j].column+" = ?")
+			args = append(args, cursor.Values[j])
+		}
+
+		op := ">"
+		if key.desc != req.Backward() {
+			op = "<"
+		}
+		parts = append(parts, key.column+" "+op+" ?")
+		args = append(args, cursor.Values[i])
+
+		alternatives = append(alternatives, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")", args, nil
+}
+
+// orderBy возвращает выражение ORDER BY для направления запроса
+func (k keyset[T]) orderBy(req pagination.Request) string {
+	columns := make([]string, 0, len(k.keys))
+	for _, key := range k.keys {
+		dir := "ASC"
+		if key.desc != req.Backward() {
+			dir = "DESC"
+		}
+		columns = append(columns, key.column+" "+dir)
+	}
+	return "ORDER BY " + strings.Join(columns, ", ")
+}
+
+// page собирает страницу из выборки, запрошенной с лимитом req.Limit+1
+func (k keyset[T]) page(items []T, req pagination.Request) pagination.Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+
+	hasExtra := len(items) > req.Limit
+	if hasExtra {
+		items = items[:req.Limit]
+	}
+	if req.Backward() {
+		slices.Reverse(items)
+	}
+
+	page := pagination.Page[T]{Items: items}
+	if len(items) == 0 {
+		return page
+	}
+
+	if req.Backward() {
+		page.NextCursor = k.cursor(items[len(items)-1])
+		if hasExtra {
+			page.PrevCursor = k.cursor(items[0])
+		}
+	} else {
+		if hasExtra {
+			page.NextCursor = k.cursor(items[len(items)-1])
+		}
+		if req.After != "" {
+			page.PrevCursor = k.cursor(items[0])
+		}
+	}
+	page.HasMore = page.NextCursor != ""
+
+	return page
+}
+
+func (k keyset[T]) cursor(item T) string {
+	values := make([]string, len(k.keys))
+	for i, key := range k.keys {
+		values[i] = key.value(item)
+	}
+	return pagination.Cursor{Sort: k.name, Values: values}.Encode()
+}
+
+func formatCursorTime(t time.Time) string {
+	return t.UTC().Format(cursorTimeLayout)
+}
+
+func formatCursorInt(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
+
+func formatCursorFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/pagination"
+)
+
+const mentionColumns = "m.id, m.post_id, m.comment_id, m.author_id, m.username, m.user_id, m.created_at"
+
+// mentionsOrder упорядочивает упоминания от новых к старым
+var mentionsOrder = keyset[*model.Mention]{name: "mentions", keys: []sortKey[*model.Mention]{
+	{column: "m.created_at", desc: true, value: func(m *model.Mention) string { return formatCursorTime(m.CreatedAt) }},
+	{column: "m.id", desc: true, value: func(m *model.Mention) string { return formatCursorInt(m.ID) }},
+}}
+
+type MentionRepository struct {
+	db *sql.DB
+}
+
+func NewMentionRepository(db *sql.DB) *MentionRepository {
+	return &MentionRepository{db: db}
+}
+
+func scanMention(row rowScanner) (*model.Mention, error) {
+	mention := &model.Mention{}
+	var (
+		commentID int64
+		userID    sql.NullInt64
+	)
+	err := row.Scan(
+		&mention.ID,
+		&mention.PostID,
+		&commentID,
+		&mention.AuthorID,
+		&mention.Username,
+		&userID,
+		&mention.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if commentID != 0 {
+		mention.CommentID = &commentID
+	}
+	if userID.Valid {
+		mention.UserID = &userID.Int64
+	}
+	return mention, nil
+}
+
+// Replace заменяет упоминания в посте или, если commentID не равен 0,
+// в комментарии. Упоминания, сохранившиеся после правки, не пересоздаются
+// и сохраняют время появления.
+func (r *MentionRepository) Replace(postID, commentID, authorID int64, mentions []*model.Mention) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "DELETE FROM mentions WHERE post_id = ? AND comment_id = ?"
+	args := []any{postID, commentID}
+	if len(mentions) > 0 {
+		query += " AND username NOT IN (" + placeholders(len(mentions)) + ")"
+		for _, mention := range mentions {
+			args = append(args, mention.Username)
+		}
+	}
+	if _, err := tx.Exec(query, args...); err != nil {
+		return err
+	}
+
+	for _, mention := range mentions {
+		query := `
+			INSERT INTO mentions (post_id, comment_id, author_id, username, user_id)
+			VALUES (?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE user_id = VALUES(user_id)
+		`
+		if _, err := tx.Exec(query, postID, commentID, authorID, mention.Username, mention.UserID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetByUser возвращает страницу упоминаний пользователя в опубликованных постах
+// и комментариях к ним
+func (r *MentionRepository) GetByUser(userID int64, req pagination.Request) (pagination.Page[*model.Mention], error) {
+	where := "WHERE m.user_id = ?"
+	args := []any{userID}
+
+	condition, cursorArgs, err := mentionsOrder.condition(req)
+	if err != nil {
+		return pagination.Page[*model.Mention]{}, err
+	}
+	if condition != "" {
+		where += " AND " + condition
+		args = append(args, cursorArgs...)
+	}
+
+	query := "SELECT " + mentionColumns + ` FROM mentions m
+		JOIN posts p ON p.id = m.post_id AND p.status = 'published' AND p.deleted_at IS NULL
+		` + where + " " + mentionsOrder.orderBy(req) + " LIMIT ?"
+	args = append(args, req.Limit+1)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return pagination.Page[*model.Mention]{}, err
+	}
+	defer rows.Close()
+
+	mentions := []*model.Mention{}
+	for rows.Next() {
+		mention, err := scanMention(rows)
+		if err != nil {
+			return pagination.Page[*model.Mention]{}, err
+		}
+		mentions = append(mentions, mention)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[*model.Mention]{}, err
+	}
+
+	return mentionsOrder.page(mentions, req), nil
+}
@@ -2,10 +2,16 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/fire9900/golang-forum/internal/config"
+
+	"github.com/go-sql-driver/mysql"
 )
 
+// erDupEntry — код ошибки MySQL о нарушении уникального ключа
+const erDupEntry = 1062
+
 func NewMySQLDB(cfg *config.Config) (*sql.DB, error) {
 	dataSourceName := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&multiStatements=true", //https://stackoverflow.com/questions/29341590/how-to-parse-time-from-database
 		cfg.DB.Username,
@@ -26,3 +32,9 @@ func NewMySQLDB(cfg *config.Config) (*sql.DB, error) {
 
 	return db, nil
 }
+
+// isDuplicateKey сообщает, нарушил ли запрос уникальный ключ
+func isDuplicateKey(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == erDupEntry
+}
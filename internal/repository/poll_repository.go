@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/fire9900/golang-forum/internal/model"
+)
+
+const pollColumns = `id, post_id, question, is_multiple, is_anonymous, hide_results, voters, closes_at,
+	closes_at IS NOT NULL AND closes_at <= NOW(), created_at`
+
+type PollRepository struct {
+	db *sql.DB
+}
+
+func NewPollRepository(db *sql.DB) *PollRepository {
+	return &PollRepository{db: db}
+}
+
+// Create создает опрос вместе с вариантами ответа. Возвращает false, если
+// у поста уже есть опрос.
+func (r *PollRepository) Create(poll *model.Poll) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// Блокировка поста исключает одновременное создание двух опросов
+	var exists bool
+	if err := tx.QueryRow("SELECT TRUE FROM posts WHERE id = ? FOR UPDATE", poll.PostID).Scan(&exists); err != nil {
+		return false, err
+	}
+	err = tx.QueryRow("SELECT TRUE FROM polls WHERE post_id = ?", poll.PostID).Scan(&exists)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+
+	query := `
+		INSERT INTO polls (post_id, question, is_multiple, is_anonymous, hide_results, closes_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	result, err := tx.Exec(query, poll.PostID, poll.Question, poll.Multiple, poll.Anonymous, poll.HideResults, poll.ClosesAt)
+	if err != nil {
+		return false, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return false, err
+	}
+
+	for i, option := range poll.Options {
+		result, err := tx.Exec("INSERT INTO poll_options (poll_id, position, text) VALUES (?, ?, ?)", id, i, option.Text)
+		if err != nil {
+			return false, err
+		}
+		if option.ID, err = result.LastInsertId(); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	poll.ID = id
+	return true, nil
+}
+
+// GetByPostID возвращает опрос поста с вариантами ответа и числом голосов
+func (r *PollRepository) GetByPostID(postID int64) (*model.Poll, error) {
+	poll := &model.Poll{}
+	var (
+		voters   int
+		closesAt sql.NullTime
+	)
+	err := r.db.QueryRow("SELECT "+pollColumns+" FROM polls WHERE post_id = ?", postID).Scan(
+		&poll.ID,
+		&poll.PostID,
+		&poll.Question,
+		&poll.Multiple,
+		&poll.Anonymous,
+		&poll.HideResults,
+		&voters,
+		&closesAt,
+		&poll.Closed,
+		&poll.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	poll.Voters = &voters
+	if closesAt.Valid {
+		poll.ClosesAt = &closesAt.Time
+	}
+
+	rows, err := r.db.Query("SELECT id, text, votes FROM poll_options WHERE poll_id = ? ORDER BY position", poll.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	poll.Options = []*model.PollOption{}
+	for rows.Next() {
+		option := &model.PollOption{}
+		var votes int
+		if err := rows.Scan(&option.ID, &option.Text, &votes); err != nil {
+			return nil, err
+		}
+		option.Votes = &votes
+		poll.Options = append(poll.Options, option)
+	}
+	return poll, rows.Err()
+}
+
+// GetUserVotes возвращает варианты, выбранные пользователем
+func (r *PollRepository) GetUserVotes(pollID, userID int64) ([]int64, error) {
+	rows, err := r.db.Query("SELECT option_id FROM poll_votes WHERE poll_id = ? AND user_id = ?", pollID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetVoters возвращает проголосовавших пользователей по вариантам ответа
+func (r *PollRepository) GetVoters(pollID int64) (map[int64][]int64, error) {
+	query := "SELECT option_id, user_id FROM poll_votes WHERE poll_id = ? ORDER BY created_at, user_id"
+	rows, err := r.db.Query(query, pollID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	voters := make(map[int64][]int64)
+	for rows.Next() {
+		var optionID, userID int64
+		if err := rows.Scan(&optionID, &userID); err != nil {
+			return nil, err
+		}
+		voters[optionID] = append(voters[optionID], userID)
+	}
+	return voters, rows.Err()
+}
+
+// Vote сохраняет голос пользователя за варианты optionIDs. Возвращает false,
+// если пользователь уже голосовал или опрос закрыт.
+func (r *PollRepository) Vote(pollID, userID int64, optionIDs []int64) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// Блокировка опроса упорядочивает голоса и закрытие опроса
+	var closed bool
+	query := "SELECT closes_at IS NOT NULL AND closes_at <= NOW() FROM polls WHERE id = ? FOR UPDATE"
+	if err := tx.QueryRow(query, pollID).Scan(&closed); err != nil {
+		return false, err
+	}
+	if closed {
+		return false, nil
+	}
+
+	var voted bool
+	err = tx.QueryRow("SELECT TRUE FROM poll_votes WHERE poll_id = ? AND user_id = ? LIMIT 1", pollID, userID).Scan(&voted)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+	if voted {
+		return false, nil
+	}
+
+	args := []any{}
+	for _, optionID := range optionIDs {
+		args = append(args, optionID)
+		if _, err := tx.Exec("INSERT INTO poll_votes (poll_id, option_id, user_id) VALUES (?, ?, ?)", pollID, optionID, userID); err != nil {
+			return false, err
+		}
+	}
+
+	query = "UPDATE poll_options SET votes = votes + 1 WHERE poll_id = ? AND id IN (" + placeholders(len(optionIDs)) + ")"
+	if _, err := tx.Exec(query, append([]any{pollID}, args...)...); err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec("UPDATE polls SET voters = voters + 1 WHERE id = ?", pollID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// Close досрочно закрывает опрос
+func (r *PollRepository) Close(pollID int64) error {
+	query := "UPDATE polls SET closes_at = NOW() WHERE id = ? AND (closes_at IS NULL OR closes_at > NOW())"
+	_, err := r.db.Exec(query, pollID)
+	return err
+}
+
+// purgePolls удаляет опросы постов postIDs в транзакции окончательного
+// удаления постов
+func purgePolls(tx *sql.Tx, postIDs []any) error {
+	in := placeholders(len(postIDs))
+	for _, table := range []string{"poll_votes", "poll_options"} {
+		query := "DELETE t FROM " + table + " t JOIN polls p ON p.id = t.poll_id WHERE p.post_id IN (" + in + ")"
+		if _, err := tx.Exec(query, postIDs...); err != nil {
+			return err
+		}
+	}
+	_, err := tx.Exec("DELETE FROM polls WHERE post_id IN ("+in+")", postIDs...)
+	return err
+}
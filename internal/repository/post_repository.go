@@ -2,9 +2,23 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/pagination"
+	"github.com/fire9900/golang-forum/internal/slug"
 )
 
+const postColumns = `id, title, slug, content, content_html, category_id, author_id, status, publish_at,
+	is_pinned, pin_scope, is_locked, is_announcement, comment_count,
+	upvotes, downvotes, score, view_count, hot_score, controversy, last_activity_at, created_at, updated_at, deleted_at, deleted_by`
+
+// publishedCondition отбирает посты, видимые всем пользователям
+const publishedCondition = "status = 'published' AND deleted_at IS NULL"
+
 type PostRepository struct {
 	db *sql.DB
 }
@@ -13,101 +27,762 @@ func NewPostRepository(db *sql.DB) *PostRepository {
 	return &PostRepository{db: db}
 }
 
+func scanPost(row rowScanner) (*model.Post, error) {
+	post := &model.Post{}
+	var (
+		contentHTML sql.NullString
+		publishAt   sql.NullTime
+		pinScope    sql.NullString
+		deletedAt   sql.NullTime
+		deletedBy   sql.NullInt64
+	)
+	err := row.Scan(
+		&post.ID,
+		&post.Title,
+		&post.Slug,
+		&post.Content,
+		&contentHTML,
+		&post.CategoryID,
+		&post.AuthorID,
+		&post.Status,
+		&publishAt,
+		&post.Pinned,
+		&pinScope,
+		&post.Locked,
+		&post.Announcement,
+		&post.CommentCount,
+		&post.Upvotes,
+		&post.Downvotes,
+		&post.Score,
+		&post.Views,
+		&post.HotScore,
+		&post.Controversy,
+		&post.LastActivityAt,
+		&post.CreatedAt,
+		&post.UpdatedAt,
+		&deletedAt,
+		&deletedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	post.ContentHTML = contentHTML.String
+	if publishAt.Valid {
+		post.PublishAt = &publishAt.Time
+	}
+	post.PinScope = pinScope.String
+	if deletedAt.Valid {
+		post.DeletedAt = &deletedAt.Time
+	}
+	if deletedBy.Valid {
+		post.DeletedBy = &deletedBy.Int64
+	}
+	return post, nil
+}
+
 func (r *PostRepository) Create(post *model.Post) error {
-	query := `
-		INSERT INTO posts (title, content, author_id, created_at, updated_at)
-		VALUES (?, ?, ?, NOW(), NOW())
-	`
-	result, err := r.db.Exec(query, post.Title, post.Content, post.AuthorID)
+	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
+
+	var id int64
+	postSlug, err := claimSlug(tx, 0, post.Slug, func(candidate string) error {
+		query := `
+			INSERT INTO posts (title, slug, content, content_html, category_id, author_id, status, publish_at,
+				last_activity_at, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW(), NOW())
+		`
+		result, err := tx.Exec(query,
+			post.Title, candidate, post.Content, post.ContentHTML, post.CategoryID, post.AuthorID, post.Status, post.PublishAt)
+		if err != nil {
+			return err
+		}
 
-	id, err := result.LastInsertId()
+		if id, err = result.LastInsertId(); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec("INSERT INTO post_slugs (slug, post_id) VALUES (?, ?)", candidate, id)
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
+	if err := replacePostTags(tx, id, post.Tags); err != nil {
+		return err
+	}
+
+	if err := addRevision(tx, id, post.Title, post.Content, post.AuthorID); err != nil {
+		return err
+	}
+
+	if err := subscribe(tx, post.AuthorID, id, model.SubscriptionWatching); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
 	post.ID = id
+	post.Slug = postSlug
 	return nil
 }
 
 func (r *PostRepository) GetByID(id int64) (*model.Post, error) {
-	post := &model.Post{}
-	query := `
-		SELECT id, title, content, author_id, created_at, updated_at
-		FROM posts WHERE id = ?
-	`
-	err := r.db.QueryRow(query, id).Scan(
-		&post.ID,
-		&post.Title,
-		&post.Content,
-		&post.AuthorID,
-		&post.CreatedAt,
-		&post.UpdatedAt,
-	)
+	return r.getOne("SELECT "+postColumns+" FROM posts WHERE id = ? AND "+publishedCondition, id)
+}
+
+// GetBySlug возвращает опубликованный пост по текущему или одному из
+// прежних slug
+func (r *PostRepository) GetBySlug(slug string) (*model.Post, error) {
+	query := "SELECT " + postColumns + " FROM posts WHERE id = (SELECT post_id FROM post_slugs WHERE slug = ?) AND " + publishedCondition
+	return r.getOne(query, slug)
+}
+
+// GetByIDAnyStatus возвращает неудаленный пост независимо от статуса
+// публикации, в том числе черновик
+func (r *PostRepository) GetByIDAnyStatus(id int64) (*model.Post, error) {
+	return r.getOne("SELECT "+postColumns+" FROM posts WHERE id = ? AND deleted_at IS NULL", id)
+}
+
+func (r *PostRepository) getOne(query string, args ...any) (*model.Post, error) {
+	post, err := scanPost(r.db.QueryRow(query, args...))
 	if err != nil {
 		return nil, err
 	}
+
+	if err := r.loadTags([]*model.Post{post}); err != nil {
+		return nil, err
+	}
 	return post, nil
 }
 
-func (r *PostRepository) GetAll(limit, offset int) ([]*model.Post, error) {
-	query := `
-		SELECT id, title, content, author_id, created_at, updated_at
-		FROM posts ORDER BY created_at DESC LIMIT ? OFFSET ?
+func postKey(column string, desc bool, value func(*model.Post) string) sortKey[*model.Post] {
+	return sortKey[*model.Post]{column: column, desc: desc, value: value}
+}
+
+func postID(p *model.Post) string { return formatCursorInt(p.ID) }
+
+// postOrders содержит поддерживаемые порядки выборки постов
+var postOrders = map[string]keyset[*model.Post]{
+	model.PostSortNewest: {name: model.PostSortNewest, keys: []sortKey[*model.Post]{
+		postKey("created_at", true, func(p *model.Post) string { return formatCursorTime(p.CreatedAt) }),
+		postKey("id", true, postID),
+	}},
+	model.PostSortOldest: {name: model.PostSortOldest, keys: []sortKey[*model.Post]{
+		postKey("created_at", false, func(p *model.Post) string { return formatCursorTime(p.CreatedAt) }),
+		postKey("id", false, postID),
+	}},
+	model.PostSortUpdated: {name: model.PostSortUpdated, keys: []sortKey[*model.Post]{
+		postKey("updated_at", true, func(p *model.Post) string { return formatCursorTime(p.UpdatedAt) }),
+		postKey("id", true, postID),
+	}},
+	model.PostSortComments: {name: model.PostSortComments, keys: []sortKey[*model.Post]{
+		postKey("comment_count", true, func(p *model.Post) string { return formatCursorInt(int64(p.CommentCount)) }),
+		postKey("id", true, postID),
+	}},
+	model.PostSortActive: {name: model.PostSortActive, keys: []sortKey[*model.Post]{
+		postKey("last_activity_at", true, func(p *model.Post) string { return formatCursorTime(p.LastActivityAt) }),
+		postKey("id", true, postID),
+	}},
+	model.PostSortTop: {name: model.PostSortTop, keys: []sortKey[*model.Post]{
+		postKey("score", true, func(p *model.Post) string { return formatCursorInt(int64(p.Score)) }),
+		postKey("id", true, postID),
+	}},
+	model.PostSortHot: {name: model.PostSortHot, keys: []sortKey[*model.Post]{
+		postKey("hot_score", true, func(p *model.Post) string { return formatCursorFloat(p.HotScore) }),
+		postKey("id", true, postID),
+	}},
+	model.PostSortControversial: {name: model.PostSortControversial, keys: []sortKey[*model.Post]{
+		postKey("controversy", true, func(p *model.Post) string { return formatCursorFloat(p.Controversy) }),
+		postKey("id", true, postID),
+	}},
+	model.PostSortViews: {name: model.PostSortViews, keys: []sortKey[*model.Post]{
+		postKey("view_count", true, func(p *model.Post) string { return formatCursorInt(int64(p.Views)) }),
+		postKey("id", true, postID),
+	}},
+}
+
+// postOrder возвращает порядок выборки, в котором объявления и закрепленные
+// посты идут перед остальными. В списке раздела учитываются и посты,
+// закрепленные только в этом разделе.
+func postOrder(filter model.PostFilter) keyset[*model.Post] {
+	order, ok := postOrders[filter.Sort]
+	if !ok {
+		order = postOrders[model.PostSortNewest]
+	}
+
+	inCategory := filter.CategoryID != 0
+	rank := "CASE WHEN is_announcement THEN 2 WHEN is_pinned AND pin_scope = 'global' THEN 1 ELSE 0 END"
+	if inCategory {
+		rank = "CASE WHEN is_announcement THEN 2 WHEN is_pinned THEN 1 ELSE 0 END"
+	}
+
+	pinKey := postKey(rank, true, func(p *model.Post) string {
+		switch {
+		case p.Announcement:
+			return "2"
+		case p.Pinned && (inCategory || p.PinScope == model.PinScopeGlobal):
+			return "1"
+		default:
+			return "0"
+		}
+	})
+
+	return keyset[*model.Post]{
+		name: order.name,
+		keys: append([]sortKey[*model.Post]{pinKey}, order.keys...),
+	}
+}
+
+func (r *PostRepository) GetAll(filter model.PostFilter, req pagination.Request) (pagination.Page[*model.Post], error) {
+	conditions, args := postConditions(filter)
+
+	order := postOrder(filter)
+	condition, cursorArgs, err := order.condition(req)
+	if err != nil {
+		return pagination.Page[*model.Post]{}, err
+	}
+	if condition != "" {
+		conditions = append(conditions, condition)
+		args = append(args, cursorArgs...)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := "SELECT " + postColumns + " FROM posts " + where + " " + order.orderBy(req) + " LIMIT ?"
+	args = append(args, req.Limit+1)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return pagination.Page[*model.Post]{}, err
+	}
+	defer rows.Close()
+
+	posts := []*model.Post{}
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return pagination.Page[*model.Post]{}, err
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[*model.Post]{}, err
+	}
+
+	page := order.page(posts, req)
+	if err := r.loadTags(page.Items); err != nil {
+		return pagination.Page[*model.Post]{}, err
+	}
+	return page, nil
+}
+
+// postConditions переводит фильтр в условия WHERE
+func postConditions(filter model.PostFilter) ([]string, []any) {
+	var (
+		conditions = []string{publishedCondition}
+		args       []any
+	)
+	if filter.CategoryID != 0 {
+		// Объявления показываются во всех разделах
+		conditions = append(conditions, "(category_id = ? OR is_announcement)")
+		args = append(args, filter.CategoryID)
+	}
+	if len(filter.Tags) > 0 {
+		condition, tagArgs := tagCondition(filter.Tags, filter.TagMode)
+		conditions = append(conditions, condition)
+		args = append(args, tagArgs...)
+	}
+	if filter.AuthorID != 0 {
+		conditions = append(conditions, "author_id = ?")
+		args = append(args, filter.AuthorID)
+	}
+	if filter.Title != "" {
+		conditions = append(conditions, "title LIKE ?")
+		args = append(args, "%"+escapeLike(filter.Title)+"%")
+	}
+	if filter.CreatedAfter != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, *filter.CreatedBefore)
+	}
+	if filter.UpdatedAfter != nil {
+		conditions = append(conditions, "updated_at >= ?")
+		args = append(args, *filter.UpdatedAfter)
+	}
+	if filter.UpdatedBefore != nil {
+		conditions = append(conditions, "updated_at < ?")
+		args = append(args, *filter.UpdatedBefore)
+	}
+	return conditions, args
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (r *PostRepository) Update(post *model.Post) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Блокируем строку поста, чтобы правки и номера ревизий шли строго по очереди
+	var prevTitle, prevSlug, prevContent, prevStatus string
+	query := "SELECT title, slug, content, status FROM posts WHERE id = ? AND author_id = ? AND deleted_at IS NULL FOR UPDATE"
+	if err := tx.QueryRow(query, post.ID, post.AuthorID).Scan(&prevTitle, &prevSlug, &prevContent, &prevStatus); err != nil {
+		return err
+	}
+
+	// Slug меняется только вместе с заголовком; прежний остается в истории
+	postSlug := prevSlug
+	if post.Title != prevTitle {
+		postSlug, err = claimSlug(tx, post.ID, post.Slug, func(candidate string) error {
+			_, err := tx.Exec("INSERT INTO post_slugs (slug, post_id) VALUES (?, ?)", candidate, post.ID)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Пост, опубликованный из черновика, появляется в ленте как новый
+	published := ""
+	if prevStatus != model.PostStatusPublished && post.Status == model.PostStatusPublished {
+		published = ", created_at = NOW(), last_activity_at = NOW()"
+	}
+
+	query = `
+		UPDATE posts 
+		SET title = ?, slug = ?, content = ?, content_html = ?, category_id = ?, status = ?, publish_at = ?,
+			updated_at = NOW()` + published + `
+		WHERE id = ?
 	`
-	rows, err := r.db.Query(query, limit, offset)
+	_, err = tx.Exec(query,
+		post.Title, postSlug, post.Content, post.ContentHTML, post.CategoryID, post.Status, post.PublishAt, post.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := replacePostTags(tx, post.ID, post.Tags); err != nil {
+		return err
+	}
+
+	if post.Title != prevTitle || post.Content != prevContent {
+		if err := addRevision(tx, post.ID, post.Title, post.Content, post.AuthorID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	post.Slug = postSlug
+	return nil
+}
+
+// maxSlugAttempts ограничивает число попыток занять slug, если свободные
+// кандидаты одновременно занимают другие посты
+const maxSlugAttempts = 10
+
+// claimSlug занимает для поста base или, если он занят другим постом, base
+// с первым свободным числовым суффиксом. Кандидат сохраняется функцией
+// insert; если его одновременно занял другой пост, insert откатывается и
+// вызывается со следующим кандидатом. Slug из истории остаются за своими
+// постами, чтобы старые адреса не начали вести на чужой пост.
+func claimSlug(tx *sql.Tx, postID int64, base string, insert func(candidate string) error) (string, error) {
+	owners, err := slugOwners(tx, base)
+	if err != nil {
+		return "", err
+	}
+
+	for attempt := 1; ; attempt++ {
+		candidate := base
+		for n := 2; !slugAvailable(owners, candidate, postID); n++ {
+			suffix := "-" + strconv.Itoa(n)
+			candidate = strings.TrimRight(base[:min(len(base), slug.MaxLength-len(suffix))], "-") + suffix
+		}
+		// Slug из истории самого поста уже принадлежит ему
+		if _, ok := owners[candidate]; ok {
+			return candidate, nil
+		}
+
+		if _, err := tx.Exec("SAVEPOINT claim_slug"); err != nil {
+			return "", err
+		}
+		err := insert(candidate)
+		if err == nil {
+			return candidate, nil
+		}
+		if !isDuplicateKey(err) || attempt == maxSlugAttempts {
+			return "", err
+		}
+		if _, err := tx.Exec("ROLLBACK TO SAVEPOINT claim_slug"); err != nil {
+			return "", err
+		}
+		// Кандидата занял другой пост, транзакция которого еще не была
+		// видна при чтении истории
+		owners[candidate] = -1
+	}
+}
+
+// slugOwners возвращает владельцев slug, совпадающих с base или
+// начинающихся с base и дефиса
+func slugOwners(tx *sql.Tx, base string) (map[string]int64, error) {
+	rows, err := tx.Query("SELECT slug, post_id FROM post_slugs WHERE slug = ? OR slug LIKE ?", base, base+"-%")
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var posts []*model.Post
+	owners := make(map[string]int64)
 	for rows.Next() {
-		post := &model.Post{}
-		err := rows.Scan(
-			&post.ID,
-			&post.Title,
-			&post.Content,
-			&post.AuthorID,
-			&post.CreatedAt,
-			&post.UpdatedAt,
+		var (
+			s      string
+			postID int64
 		)
+		if err := rows.Scan(&s, &postID); err != nil {
+			return nil, err
+		}
+		owners[s] = postID
+	}
+	return owners, rows.Err()
+}
+
+// slugAvailable сообщает, может ли пост postID занять candidate
+func slugAvailable(owners map[string]int64, candidate string, postID int64) bool {
+	owner, ok := owners[candidate]
+	return !ok || owner == postID
+}
+
+// SetPinned закрепляет пост в области scope или снимает закрепление,
+// если scope пуст
+func (r *PostRepository) SetPinned(id int64, scope string) error {
+	query := `
+		UPDATE posts
+		SET is_pinned = ?, pin_scope = NULLIF(?, ''), updated_at = updated_at
+		WHERE id = ? AND deleted_at IS NULL
+	`
+	return execPostUpdate(r.db, query, scope != "", scope, id)
+}
+
+func (r *PostRepository) SetLocked(id int64, locked bool) error {
+	query := "UPDATE posts SET is_locked = ?, updated_at = updated_at WHERE id = ? AND deleted_at IS NULL"
+	return execPostUpdate(r.db, query, locked, id)
+}
+
+func (r *PostRepository) SetAnnouncement(id int64, announcement bool) error {
+	query := "UPDATE posts SET is_announcement = ?, updated_at = updated_at WHERE id = ? AND deleted_at IS NULL"
+	return execPostUpdate(r.db, query, announcement, id)
+}
+
+// Delete переносит пост в корзину. Модератор может удалить любой пост,
+// остальные пользователи — только свои.
+func (r *PostRepository) Delete(id, userID int64, asModerator bool) error {
+	query := `
+		UPDATE posts
+		SET deleted_at = NOW(), deleted_by = ?, updated_at = updated_at
+		WHERE id = ? AND deleted_at IS NULL
+	`
+	args := []any{userID, id}
+	if !asModerator {
+		query += " AND author_id = ?"
+		args = append(args, userID)
+	}
+	return execAffectingRow(r.db, query, args...)
+}
+
+// Restore возвращает пост из корзины. Автор может восстановить только
+// пост, который удалил сам, но не удаленный модератором.
+func (r *PostRepository) Restore(id, userID int64, asModerator bool) error {
+	query := `
+		UPDATE posts
+		SET deleted_at = NULL, deleted_by = NULL, updated_at = updated_at
+		WHERE id = ? AND deleted_at IS NOT NULL
+	`
+	args := []any{id}
+	if !asModerator {
+		query += " AND author_id = ? AND deleted_by = author_id"
+		args = append(args, userID)
+	}
+	return execAffectingRow(r.db, query, args...)
+}
+
+// GetDraftsByAuthor возвращает неопубликованные посты автора:
+// черновики и отложенные публикации
+func (r *PostRepository) GetDraftsByAuthor(authorID int64) ([]*model.Post, error) {
+	query := "SELECT " + postColumns + ` FROM posts
+		WHERE author_id = ? AND status <> 'published' AND deleted_at IS NULL
+		ORDER BY updated_at DESC, id DESC`
+	return r.getMany(query, authorID)
+}
+
+// PublishDue публикует до limit отложенных постов, время публикации которых
+// наступило, и возвращает их идентификаторы. Строки, заблокированные другим
+// экземпляром приложения, пропускаются, поэтому каждый пост публикуется
+// ровно один раз.
+func (r *PostRepository) PublishDue(limit int) ([]int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	ids, err := selectIDs(tx, `
+		SELECT id FROM posts
+		WHERE status = 'scheduled' AND publish_at <= NOW() AND deleted_at IS NULL
+		ORDER BY publish_at LIMIT ?
+		FOR UPDATE SKIP LOCKED
+	`, limit)
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	query := `
+		UPDATE posts
+		SET status = 'published', created_at = publish_at, last_activity_at = publish_at, updated_at = updated_at
+		WHERE id IN (` + placeholders(len(ids)) + `)
+	`
+	if _, err := tx.Exec(query, args...); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// GetDeletedByAuthor возвращает посты, которые автор удалил сам. Посты,
+// удаленные модератором, автор восстановить не может, и в корзине их нет.
+func (r *PostRepository) GetDeletedByAuthor(authorID int64) ([]*model.Post, error) {
+	query := "SELECT " + postColumns + ` FROM posts
+		WHERE author_id = ? AND deleted_at IS NOT NULL AND deleted_by = author_id
+		ORDER BY deleted_at DESC, id DESC`
+	return r.getMany(query, authorID)
+}
+
+// AddViews увеличивает счетчики просмотров постов на значения из views
+// одним запросом
+func (r *PostRepository) AddViews(views map[int64]int) error {
+	if len(views) == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+	args := make([]any, 0, len(views)*3)
+	ids := make([]any, 0, len(views))
+	b.WriteString("UPDATE posts SET view_count = view_count + CASE id")
+	for id, count := range views {
+		b.WriteString(" WHEN ? THEN ?")
+		args = append(args, id, count)
+		ids = append(ids, id)
+	}
+	b.WriteString(" END, updated_at = updated_at WHERE id IN (" + placeholders(len(ids)) + ")")
+
+	_, err := r.db.Exec(b.String(), append(args, ids...)...)
+	return err
+}
+
+// GetUnrendered возвращает до limit постов без отрисованного HTML:
+// идентификаторы и исходный текст
+func (r *PostRepository) GetUnrendered(limit int) (map[int64]string, error) {
+	return selectUnrendered(r.db, "SELECT id, content FROM posts WHERE content_html IS NULL LIMIT ?", limit)
+}
+
+// SetContentHTML сохраняет отрисованный HTML поста, если он еще не был
+// сохранен вместе с новым текстом
+func (r *PostRepository) SetContentHTML(id int64, html string) error {
+	query := "UPDATE posts SET content_html = ?, updated_at = updated_at WHERE id = ? AND content_html IS NULL"
+	_, err := r.db.Exec(query, html, id)
+	return err
+}
+
+// GetByIDs возвращает опубликованные посты с указанными идентификаторами
+func (r *PostRepository) GetByIDs(ids []int64) ([]*model.Post, error) {
+	if len(ids) == 0 {
+		return []*model.Post{}, nil
+	}
+
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	query := "SELECT " + postColumns + " FROM posts WHERE id IN (" + placeholders(len(ids)) + ") AND " + publishedCondition
+	return r.getMany(query, args...)
+}
+
+func (r *PostRepository) getMany(query string, args ...any) ([]*model.Post, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []*model.Post{}
+	for rows.Next() {
+		post, err := scanPost(rows)
 		if err != nil {
 			return nil, err
 		}
 		posts = append(posts, post)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadTags(posts); err != nil {
+		return nil, err
+	}
 	return posts, nil
 }
 
-func (r *PostRepository) Update(post *model.Post) error {
+// Purge окончательно удаляет до limit постов, находящихся в корзине дольше
+// retention, вместе с их комментариями, тегами, ревизиями, голосами,
+// реакциями, закладками, подписками, опросами, историей slug и
+// упоминаниями. Вложения постов открепляются. Возвращает количество
+// удаленных постов.
+func (r *PostRepository) Purge(retention time.Duration, limit int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	ids, err := selectIDs(tx, `
+		SELECT id FROM posts
+		WHERE deleted_at < NOW() - INTERVAL ? SECOND
+		ORDER BY deleted_at LIMIT ? FOR UPDATE
+	`, int64(retention.Seconds()), limit)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	in := placeholders(len(ids))
 	query := `
-		UPDATE posts 
-		SET title = ?, content = ?, updated_at = NOW()
-		WHERE id = ? AND author_id = ?
+		DELETE cv FROM comment_votes cv
+		JOIN comments c ON c.id = cv.comment_id
+		WHERE c.post_id IN (` + in + `)
 	`
-	result, err := r.db.Exec(query, post.Title, post.Content, post.ID, post.AuthorID)
-	if err != nil {
+	if _, err := tx.Exec(query, args...); err != nil {
+		return 0, err
+	}
+	query = `
+		DELETE rc FROM reactions rc
+		JOIN comments c ON rc.target_type = 'comment' AND c.id = rc.target_id
+		WHERE c.post_id IN (` + in + `)
+	`
+	if _, err := tx.Exec(query, args...); err != nil {
+		return 0, err
+	}
+	query = "DELETE FROM reactions WHERE target_type = 'post' AND target_id IN (" + in + ")"
+	if _, err := tx.Exec(query, args...); err != nil {
+		return 0, err
+	}
+	if err := purgePolls(tx, args); err != nil {
+		return 0, err
+	}
+	// Содержимое открепленных вложений удаляет отдельная фоновая задача
+	query = "UPDATE attachments SET post_id = NULL, comment_id = NULL WHERE post_id IN (" + in + ")"
+	if _, err := tx.Exec(query, args...); err != nil {
+		return 0, err
+	}
+	for _, table := range []string{"comments", "post_tags", "post_revisions", "post_votes", "bookmarks", "subscriptions", "post_slugs", "mentions"} {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE post_id IN ("+in+")", args...); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := tx.Exec("DELETE FROM posts WHERE id IN ("+in+")", args...); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// execPostUpdate выполняет запрос изменения флагов поста, последним
+// аргументом которого является id поста. MySQL не считает
+// затронутыми строки, значения в которых не изменились, поэтому при отсутствии
+// изменений существование поста проверяется отдельно.
+func execPostUpdate(db *sql.DB, query string, args ...any) error {
+	err := execAffectingRow(db, query, args...)
+	if !errors.Is(err, sql.ErrNoRows) {
 		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	id := args[len(args)-1]
+	var exists bool
+	return db.QueryRow("SELECT TRUE FROM posts WHERE id = ? AND deleted_at IS NULL", id).Scan(&exists)
+}
+
+// selectUnrendered выполняет запрос, возвращающий идентификаторы и
+// исходный текст записей
+func selectUnrendered(db *sql.DB, query string, args ...any) (map[int64]string, error) {
+	rows, err := db.Query(query, args...)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer rows.Close()
 
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
+	contents := make(map[int64]string)
+	for rows.Next() {
+		var (
+			id      int64
+			content string
+		)
+		if err := rows.Scan(&id, &content); err != nil {
+			return nil, err
+		}
+		contents[id] = content
 	}
+	return contents, rows.Err()
+}
 
-	return nil
+// selectIDs выполняет в транзакции запрос, возвращающий столбец идентификаторов
+func selectIDs(tx *sql.Tx, query string, args ...any) ([]int64, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
 }
 
-func (r *PostRepository) Delete(id, authorID int64) error {
-	query := "DELETE FROM posts WHERE id = ? AND author_id = ?"
-	result, err := r.db.Exec(query, id, authorID)
+// execAffectingRow выполняет запрос и возвращает sql.ErrNoRows,
+// если он не затронул ни одной строки
+func execAffectingRow(db *sql.DB, query string, args ...any) error {
+	result, err := db.Exec(query, args...)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/pagination"
+)
+
+// reactorsOrder упорядочивает пользователей по времени реакции
+var reactorsOrder = keyset[*model.Reactor]{name: "reactors", keys: []sortKey[*model.Reactor]{
+	{column: "created_at", value: func(r *model.Reactor) string { return formatCursorTime(r.CreatedAt) }},
+	{column: "user_id", value: func(r *model.Reactor) string { return formatCursorInt(r.UserID) }},
+}}
+
+type ReactionRepository struct {
+	db *sql.DB
+}
+
+func NewReactionRepository(db *sql.DB) *ReactionRepository {
+	return &ReactionRepository{db: db}
+}
+
+// Add добавляет реакцию пользователя. Повторная реакция тем же эмодзи
+// игнорируется.
+func (r *ReactionRepository) Add(targetType string, targetID, userID int64, emoji string) error {
+	query := "INSERT IGNORE INTO reactions (target_type, target_id, user_id, emoji) VALUES (?, ?, ?, ?)"
+	_, err := r.db.Exec(query, targetType, targetID, userID, emoji)
+	return err
+}
+
+// Remove удаляет реакцию пользователя
+func (r *ReactionRepository) Remove(targetType string, targetID, userID int64, emoji string) error {
+	query := "DELETE FROM reactions WHERE target_type = ? AND target_id = ? AND user_id = ? AND emoji = ?"
+	_, err := r.db.Exec(query, targetType, targetID, userID, emoji)
+	return err
+}
+
+// Summary возвращает число реакций с каждым эмодзи и реакции пользователя
+// userID. Для анонимного пользователя userID равен 0.
+func (r *ReactionRepository) Summary(targetType string, targetID, userID int64) (*model.ReactionSummary, error) {
+	query := `
+		SELECT emoji, COUNT(*), MAX(user_id = ?)
+		FROM reactions
+		WHERE target_type = ? AND target_id = ?
+		GROUP BY emoji
+	`
+	rows, err := r.db.Query(query, userID, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summary := &model.ReactionSummary{Counts: []model.ReactionCount{}, Mine: []string{}}
+	for rows.Next() {
+		var (
+			count model.ReactionCount
+			mine  bool
+		)
+		if err := rows.Scan(&count.Emoji, &count.Count, &mine); err != nil {
+			return nil, err
+		}
+		summary.Counts = append(summary.Counts, count)
+		if mine {
+			summary.Mine = append(summary.Mine, count.Emoji)
+		}
+	}
+	return summary, rows.Err()
+}
+
+// GetReactors возвращает страницу пользователей, оставивших реакцию emoji
+func (r *ReactionRepository) GetReactors(targetType string, targetID int64, emoji string, req pagination.Request) (pagination.Page[*model.Reactor], error) {
+	where := "WHERE target_type = ? AND target_id = ? AND emoji = ?"
+	args := []any{targetType, targetID, emoji}
+
+	condition, cursorArgs, err := reactorsOrder.condition(req)
+	if err != nil {
+		return pagination.Page[*model.Reactor]{}, err
+	}
+	if condition != "" {
+		where += " AND " + condition
+		args = append(args, cursorArgs...)
+	}
+
+	query := "SELECT user_id, created_at FROM reactions " + where + " " + reactorsOrder.orderBy(req) + " LIMIT ?"
+	args = append(args, req.Limit+1)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return pagination.Page[*model.Reactor]{}, err
+	}
+	defer rows.Close()
+
+	reactors := []*model.Reactor{}
+	for rows.Next() {
+		reactor := &model.Reactor{}
+		if err := rows.Scan(&reactor.UserID, &reactor.CreatedAt); err != nil {
+			return pagination.Page[*model.Reactor]{}, err
+		}
+		reactors = append(reactors, reactor)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[*model.Reactor]{}, err
+	}
+
+	return reactorsOrder.page(reactors, req), nil
+}
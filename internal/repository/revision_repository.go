@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/fire9900/golang-forum/internal/model"
+)
+
+const revisionColumns = "id, post_id, revision, title, content, editor_id, created_at"
+
+type RevisionRepository struct {
+	db *sql.DB
+}
+
+func NewRevisionRepository(db *sql.DB) *RevisionRepository {
+	return &RevisionRepository{db: db}
+}
+
+func scanRevision(row rowScanner) (*model.PostRevision, error) {
+	revision := &model.PostRevision{}
+	err := row.Scan(
+		&revision.ID,
+		&revision.PostID,
+		&revision.Revision,
+		&revision.Title,
+		&revision.Content,
+		&revision.EditorID,
+		&revision.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// GetByPostID возвращает все ревизии поста от первой к последней
+func (r *RevisionRepository) GetByPostID(postID int64) ([]*model.PostRevision, error) {
+	query := "SELECT " + revisionColumns + " FROM post_revisions WHERE post_id = ? ORDER BY revision ASC"
+	rows, err := r.db.Query(query, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []*model.PostRevision{}
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, rows.Err()
+}
+
+func (r *RevisionRepository) GetByNumber(postID int64, number int) (*model.PostRevision, error) {
+	query := "SELECT " + revisionColumns + " FROM post_revisions WHERE post_id = ? AND revision = ?"
+	return scanRevision(r.db.QueryRow(query, postID, number))
+}
+
+// addRevision сохраняет очередную ревизию поста. Вызывается внутри
+// транзакции, удерживающей блокировку строки поста.
+func addRevision(tx *sql.Tx, postID int64, title, content string, editorID int64) error {
+	query := `
+		INSERT INTO post_revisions (post_id, revision, title, content, editor_id, created_at)
+		SELECT ?, COALESCE(MAX(revision), 0) + 1, ?, ?, ?, NOW()
+		FROM post_revisions WHERE post_id = ?
+	`
+	_, err := tx.Exec(query, postID, title, content, editorID, postID)
+	return err
+}
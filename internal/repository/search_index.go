@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/fire9900/golang-forum/internal/model"
+)
+
+// MySQLSearchIndex выполняет полнотекстовый поиск по FULLTEXT-индексам MySQL
+type MySQLSearchIndex struct {
+	db *sql.DB
+}
+
+func NewMySQLSearchIndex(db *sql.DB) *MySQLSearchIndex {
+	return &MySQLSearchIndex{db: db}
+}
+
+// Search ищет посты и комментарии, упорядочивая их по релевантности.
+// Совпадение в заголовке поста весит вдвое больше совпадения в тексте.
+func (i *MySQLSearchIndex) Search(query string, limit, offset int) ([]*model.SearchHit, int, error) {
+	var total int
+	countQuery := `
+		SELECT
+			(SELECT COUNT(*) FROM posts p
+				WHERE p.status = 'published' AND p.deleted_at IS NULL
+					AND MATCH(p.title, p.content) AGAINST (? IN NATURAL LANGUAGE MODE))
+			+
+			(SELECT COUNT(*) FROM comments c
+				JOIN posts p ON p.id = c.post_id
+				WHERE p.status = 'published' AND p.deleted_at IS NULL
+					AND MATCH(c.content) AGAINST (? IN NATURAL LANGUAGE MODE))
+	`
+	if err := i.db.QueryRow(countQuery, query, query).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	searchQuery := `
+		SELECT kind, id, post_id, author_id, title, content, score, created_at FROM (
+			SELECT 'post' AS kind, p.id, p.id AS post_id, p.author_id, p.title, p.content, p.created_at,
+				MATCH(p.title) AGAINST (? IN NATURAL LANGUAGE MODE) * 2
+				+ MATCH(p.title, p.content) AGAINST (? IN NATURAL LANGUAGE MODE) AS score
+			FROM posts p
+			WHERE p.status = 'published' AND p.deleted_at IS NULL
+				AND MATCH(p.title, p.content) AGAINST (? IN NATURAL LANGUAGE MODE)
+			UNION ALL
+			SELECT 'comment' AS kind, c.id, c.post_id, c.author_id, p.title, c.content, c.created_at,
+				MATCH(c.content) AGAINST (? IN NATURAL LANGUAGE MODE) AS score
+			FROM comments c
+			JOIN posts p ON p.id = c.post_id
+			WHERE p.status = 'published' AND p.deleted_at IS NULL
+				AND MATCH(c.content) AGAINST (? IN NATURAL LANGUAGE MODE)
+		) results
+		ORDER BY score DESC, created_at DESC
+		LIMIT ? OFFSET ?
+	`
+	rows, err := i.db.Query(searchQuery, query, query, query, query, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	hits := []*model.SearchHit{}
+	for rows.Next() {
+		hit := &model.SearchHit{}
+		err := rows.Scan(
+			&hit.Type,
+			&hit.ID,
+			&hit.PostID,
+			&hit.AuthorID,
+			&hit.Title,
+			&hit.Content,
+			&hit.Score,
+			&hit.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return hits, total, nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/pagination"
+)
+
+const subscriptionColumns = "s.post_id, s.level, s.created_at, s.updated_at"
+
+// subscriptionsOrder упорядочивает подписки от новых к старым
+var subscriptionsOrder = keyset[*model.Subscription]{name: "subscriptions", keys: []sortKey[*model.Subscription]{
+	{column: "s.created_at", desc: true, value: func(s *model.Subscription) string { return formatCursorTime(s.CreatedAt) }},
+	{column: "s.post_id", desc: true, value: func(s *model.Subscription) string { return formatCursorInt(s.PostID) }},
+}}
+
+type SubscriptionRepository struct {
+	db *sql.DB
+}
+
+func NewSubscriptionRepository(db *sql.DB) *SubscriptionRepository {
+	return &SubscriptionRepository{db: db}
+}
+
+// Set устанавливает уровень подписки пользователя на тему
+func (r *SubscriptionRepository) Set(userID, postID int64, level string) (*model.Subscription, error) {
+	query := `
+		INSERT INTO subscriptions (user_id, post_id, level) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE level = VALUES(level)
+	`
+	if _, err := r.db.Exec(query, userID, postID, level); err != nil {
+		return nil, err
+	}
+	return r.Get(userID, postID)
+}
+
+// Get возвращает подписку пользователя на тему
+func (r *SubscriptionRepository) Get(userID, postID int64) (*model.Subscription, error) {
+	query := "SELECT " + subscriptionColumns + " FROM subscriptions s WHERE s.user_id = ? AND s.post_id = ?"
+	return scanSubscription(r.db.QueryRow(query, userID, postID))
+}
+
+// Delete отменяет подписку пользователя на тему
+func (r *SubscriptionRepository) Delete(userID, postID int64) error {
+	return execAffectingRow(r.db, "DELETE FROM subscriptions WHERE user_id = ? AND post_id = ?", userID, postID)
+}
+
+// GetByUser возвращает страницу подписок пользователя на опубликованные
+// темы. Если level не пуст, выбираются только подписки этого уровня.
+func (r *SubscriptionRepository) GetByUser(userID int64, level string, req pagination.Request) (pagination.Page[*model.Subscription], error) {
+	where := "WHERE s.user_id = ?"
+	args := []any{userID}
+	if level != "" {
+		where += " AND s.level = ?"
+		args = append(args, level)
+	}
+
+	condition, cursorArgs, err := subscriptionsOrder.condition(req)
+	if err != nil {
+		return pagination.Page[*model.Subscription]{}, err
+	}
+	if condition != "" {
+		where += " AND " + condition
+		args = append(args, cursorArgs...)
+	}
+
+	query := "SELECT " + subscriptionColumns + ` FROM subscriptions s
+		JOIN posts p ON p.id = s.post_id AND p.status = 'published' AND p.deleted_at IS NULL
+		` + where + " " + subscriptionsOrder.orderBy(req) + " LIMIT ?"
+	args = append(args, req.Limit+1)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return pagination.Page[*model.Subscription]{}, err
+	}
+	defer rows.Close()
+
+	subscriptions := []*model.Subscription{}
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			return pagination.Page[*model.Subscription]{}, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[*model.Subscription]{}, err
+	}
+
+	return subscriptionsOrder.page(subscriptions, req), nil
+}
+
+// subscribe автоматически подписывает пользователя на тему в транзакции
+// создания поста или комментария. Существующая подписка, в том числе
+// заглушенная, не меняется.
+func subscribe(tx *sql.Tx, userID, postID int64, level string) error {
+	_, err := tx.Exec("INSERT IGNORE INTO subscriptions (user_id, post_id, level) VALUES (?, ?, ?)", userID, postID, level)
+	return err
+}
+
+func scanSubscription(row rowScanner) (*model.Subscription, error) {
+	subscription := &model.Subscription{}
+	err := row.Scan(&subscription.PostID, &subscription.Level, &subscription.CreatedAt, &subscription.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/fire9900/golang-forum/internal/model"
+)
+
+type TagRepository struct {
+	db *sql.DB
+}
+
+func NewTagRepository(db *sql.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+// GetAll возвращает используемые теги вместе с количеством постов
+func (r *TagRepository) GetAll() ([]*model.Tag, error) {
+	query := `
+		SELECT t.id, t.name, COUNT(pt.post_id) AS usage_count
+		FROM tags t
+		JOIN post_tags pt ON pt.tag_id = t.id
+		JOIN posts p ON p.id = pt.post_id AND p.status = 'published' AND p.deleted_at IS NULL
+		GROUP BY t.id, t.name
+		ORDER BY usage_count DESC, t.name ASC
+	`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []*model.Tag{}
+	for rows.Next() {
+		tag := &model.Tag{}
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// replacePostTags заменяет набор тегов поста, создавая недостающие теги
+func replacePostTags(tx *sql.Tx, postID int64, tags []string) error {
+	if _, err := tx.Exec("DELETE FROM post_tags WHERE post_id = ?", postID); err != nil {
+		return err
+	}
+
+	for _, name := range tags {
+		// LAST_INSERT_ID(id) возвращает id уже существующего тега
+		result, err := tx.Exec(
+			"INSERT INTO tags (name) VALUES (?) ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)",
+			name,
+		)
+		if err != nil {
+			return err
+		}
+
+		tagID, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec("INSERT INTO post_tags (post_id, tag_id) VALUES (?, ?)", postID, tagID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tagCondition строит условие отбора постов по тегам в режиме AND или OR
+func tagCondition(tags []string, mode string) (string, []any) {
+	args := make([]any, 0, len(tags)+1)
+	for _, tag := range tags {
+		args = append(args, tag)
+	}
+
+	condition := `id IN (
+		SELECT pt.post_id FROM post_tags pt
+		JOIN tags t ON t.id = pt.tag_id
+		WHERE t.name IN (` + placeholders(len(tags)) + `)`
+	if mode == model.TagModeAnd {
+		condition += " GROUP BY pt.post_id HAVING COUNT(DISTINCT t.id) = ?"
+		args = append(args, len(tags))
+	}
+	condition += ")"
+
+	return condition, args
+}
+
+// loadTags заполняет теги для списка постов одним запросом
+func (r *PostRepository) loadTags(posts []*model.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*model.Post, len(posts))
+	args := make([]any, 0, len(posts))
+	for _, post := range posts {
+		post.Tags = []string{}
+		byID[post.ID] = post
+		args = append(args, post.ID)
+	}
+
+	query := `
+		SELECT pt.post_id, t.name
+		FROM post_tags pt
+		JOIN tags t ON t.id = pt.tag_id
+		WHERE pt.post_id IN (` + placeholders(len(args)) + `)
+		ORDER BY t.name ASC
+	`
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			postID int64
+			name   string
+		)
+		if err := rows.Scan(&postID, &name); err != nil {
+			return err
+		}
+		if post, ok := byID[postID]; ok {
+			post.Tags = append(post.Tags, name)
+		}
+	}
+	return rows.Err()
+}
+
+// placeholders возвращает список из n плейсхолдеров для конструкции IN
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
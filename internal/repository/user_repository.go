@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/fire9900/golang-forum/internal/model"
+)
+
+// userProfileColumns выбирает публичные поля пользователя вместе с числом
+// его опубликованных постов и комментариев к ним
+const userProfileColumns = `u.id, u.username,
+	(SELECT COUNT(*) FROM posts p WHERE p.author_id = u.id AND p.status = 'published' AND p.deleted_at IS NULL),
+	(SELECT COUNT(*) FROM comments c
+		JOIN posts p ON p.id = c.post_id AND p.status = 'published' AND p.deleted_at IS NULL
+		WHERE c.author_id = u.id),
+	u.created_at, u.updated_at`
+
+type UserRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// Upsert сохраняет пользователя, полученного от сервиса авторизации, и
+// сопоставляет с ним упоминания его имени, сделанные до этого
+func (r *UserRepository) Upsert(user *model.User) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Имя, перешедшее к другому пользователю, освобождается прежним владельцем
+	query := "DELETE FROM users WHERE username = ? AND id <> ?"
+	if _, err := tx.Exec(query, user.Username, user.ID); err != nil {
+		return err
+	}
+
+	query = `
+		INSERT INTO users (id, username, email) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE username = VALUES(username), email = VALUES(email)
+	`
+	if _, err := tx.Exec(query, user.ID, user.Username, user.Email); err != nil {
+		return err
+	}
+
+	query = "UPDATE mentions SET user_id = ? WHERE username = ? AND user_id IS NULL AND author_id <> ?"
+	if _, err := tx.Exec(query, user.ID, user.Username, user.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetProfile возвращает публичный профиль пользователя
+func (r *UserRepository) GetProfile(id int64) (*model.User, error) {
+	return scanUserProfile(r.db.QueryRow("SELECT "+userProfileColumns+" FROM users u WHERE u.id = ?", id))
+}
+
+// GetProfileByUsername возвращает публичный профиль пользователя по имени
+func (r *UserRepository) GetProfileByUsername(username string) (*model.User, error) {
+	return scanUserProfile(r.db.QueryRow("SELECT "+userProfileColumns+" FROM users u WHERE u.username = ?", username))
+}
+
+// LookupUsernames возвращает идентификаторы пользователей с указанными
+// именами. Ключи результата — имена в нижнем регистре.
+func (r *UserRepository) LookupUsernames(usernames []string) (map[string]int64, error) {
+	ids := make(map[string]int64, len(usernames))
+	if len(usernames) == 0 {
+		return ids, nil
+	}
+
+	args := make([]any, len(usernames))
+	for i, username := range usernames {
+		args[i] = username
+	}
+	query := "SELECT id, username FROM users WHERE username IN (" + placeholders(len(usernames)) + ")"
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id       int64
+			username string
+		)
+		if err := rows.Scan(&id, &username); err != nil {
+			return nil, err
+		}
+		ids[strings.ToLower(username)] = id
+	}
+	return ids, rows.Err()
+}
+
+// GetAuthors возвращает сведения о пользователях с указанными
+// идентификаторами одним запросом
+func (r *UserRepository) GetAuthors(ids []int64) (map[int64]*model.Author, error) {
+	authors := make(map[int64]*model.Author, len(ids))
+	if len(ids) == 0 {
+		return authors, nil
+	}
+
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := r.db.Query("SELECT id, username FROM users WHERE id IN ("+placeholders(len(ids))+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		author := &model.Author{}
+		if err := rows.Scan(&author.ID, &author.Username); err != nil {
+			return nil, err
+		}
+		authors[author.ID] = author
+	}
+	return authors, rows.Err()
+}
+
+// Exists сообщает, есть ли пользователь в каталоге
+func (r *UserRepository) Exists(id int64) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE id = ?)", id).Scan(&exists)
+	return exists, err
+}
+
+func scanUserProfile(row rowScanner) (*model.User, error) {
+	user := &model.User{}
+	err := row.Scan(&user.ID, &user.Username, &user.PostCount, &user.CommentCount, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// lockUser блокирует строку пользователя в user_locks до конца транзакции,
+// создавая ее при необходимости. Так упорядочиваются операции, которые
+// читают и изменяют строки пользователя, в том числе когда их еще нет.
+func lockUser(tx *sql.Tx, userID int64) error {
+	_, err := tx.Exec("INSERT INTO user_locks (user_id) VALUES (?) ON DUPLICATE KEY UPDATE user_id = user_id", userID)
+	return err
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/fire9900/golang-forum/internal/model"
+)
+
+// hotScoreExpr вычисляет рейтинг "hot": счет, деленный на возраст поста в
+// часах в степени 1.8, поэтому новые посты с хорошим счетом поднимаются вверх,
+// а со временем опускаются
+const hotScoreExpr = "score / POW(TIMESTAMPDIFF(SECOND, created_at, NOW()) / 3600 + 2, 1.8)"
+
+// controversyExpr вычисляет спорность: чем больше голосов и чем ближе число
+// голосов за и против, тем выше значение
+const controversyExpr = `IF(upvotes > 0 AND downvotes > 0,
+	POW(upvotes + downvotes, LEAST(upvotes, downvotes) / GREATEST(upvotes, downvotes)), 0)`
+
+// voteTarget описывает таблицы объекта голосования
+type voteTarget struct {
+	table      string // таблица объекта со счетчиками голосов
+	votesTable string // таблица голосов
+	column     string // столбец идентификатора объекта в таблице голосов
+	extraSet   string // дополнительные пересчитываемые столбцы
+}
+
+var (
+	postVotes = voteTarget{
+		table:      "posts",
+		votesTable: "post_votes",
+		column:     "post_id",
+		extraSet:   ", controversy = " + controversyExpr + ", hot_score = " + hotScoreExpr,
+	}
+	commentVotes = voteTarget{
+		table:      "comments",
+		votesTable: "comment_votes",
+		column:     "comment_id",
+	}
+)
+
+type VoteRepository struct {
+	db *sql.DB
+}
+
+func NewVoteRepository(db *sql.DB) *VoteRepository {
+	return &VoteRepository{db: db}
+}
+
+// VotePost устанавливает голос пользователя за пост. Значение 0 отзывает голос.
+func (r *VoteRepository) VotePost(postID, userID int64, value int) (*model.VoteResult, error) {
+	return r.vote(postVotes, postID, userID, value)
+}
+
+// VoteComment устанавливает голос пользователя за комментарий
+func (r *VoteRepository) VoteComment(commentID, userID int64, value int) (*model.VoteResult, error) {
+	return r.vote(commentVotes, commentID, userID, value)
+}
+
+func (r *VoteRepository) vote(target voteTarget, id, userID int64, value int) (*model.VoteResult, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Блокировка строки объекта упорядочивает голоса и изменения счетчиков
+	var exists bool
+	if err := tx.QueryRow("SELECT TRUE FROM "+target.table+" WHERE id = ? FOR UPDATE", id).Scan(&exists); err != nil {
+		return nil, err
+	}
+
+	var previous int
+	err = tx.QueryRow(
+		"SELECT value FROM "+target.votesTable+" WHERE "+target.column+" = ? AND user_id = ?",
+		id, userID,
+	).Scan(&previous)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	if value == 0 {
+		_, err = tx.Exec("DELETE FROM "+target.votesTable+" WHERE "+target.column+" = ? AND user_id = ?", id, userID)
+	} else {
+		_, err = tx.Exec(
+			"INSERT INTO "+target.votesTable+" ("+target.column+", user_id, value) VALUES (?, ?, ?)"+
+				" ON DUPLICATE KEY UPDATE value = VALUES(value)",
+			id, userID, value,
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	upDelta := boolToInt(value == 1) - boolToInt(previous == 1)
+	downDelta := boolToInt(value == -1) - boolToInt(previous == -1)
+
+	// MySQL выполняет присваивания слева направо, поэтому score и производные
+	// рейтинги вычисляются по уже обновленным счетчикам
+	query := `
+		UPDATE ` + target.table + `
+		SET upvotes = upvotes + ?, downvotes = downvotes + ?, score = upvotes - downvotes` + target.extraSet + `,
+			updated_at = updated_at
+		WHERE id = ?
+	`
+	if _, err := tx.Exec(query, upDelta, downDelta, id); err != nil {
+		return nil, err
+	}
+
+	result := &model.VoteResult{MyVote: value}
+	err = tx.QueryRow("SELECT upvotes, downvotes, score FROM "+target.table+" WHERE id = ?", id).
+		Scan(&result.Upvotes, &result.Downvotes, &result.Score)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RefreshHotScores пересчитывает рейтинг "hot" постов, опубликованных за
+// последний window. Рейтинг более старых постов обнуляется.
+func (r *VoteRepository) RefreshHotScores(window time.Duration) error {
+	seconds := int64(window.Seconds())
+
+	query := `UPDATE posts SET hot_score = ` + hotScoreExpr + `, updated_at = updated_at
+		WHERE created_at >= NOW() - INTERVAL ? SECOND`
+	if _, err := r.db.Exec(query, seconds); err != nil {
+		return err
+	}
+
+	query = `UPDATE posts SET hot_score = 0, updated_at = updated_at
+		WHERE created_at < NOW() - INTERVAL ? SECOND AND hot_score <> 0`
+	_, err := r.db.Exec(query, seconds)
+	return err
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
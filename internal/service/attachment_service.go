@@ -0,0 +1,388 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fire9900/golang-forum/internal/config"
+	"github.com/fire9900/golang-forum/internal/imaging"
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/repository"
+	"github.com/fire9900/golang-forum/internal/storage"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+const (
+	// sniffLength — объем начала файла, по которому определяется его тип
+	sniffLength = 3072
+	// maxFilenameLength ограничивает длину сохраняемого имени файла
+	maxFilenameLength = 255
+)
+
+// Upload описывает загружаемый файл
+type Upload struct {
+	Filename string
+	Size     int64
+	Content  io.Reader
+}
+
+type AttachmentService struct {
+	repo        *repository.AttachmentRepository
+	postRepo    *repository.PostRepository
+	commentRepo *repository.CommentRepository
+	store       storage.BlobStore
+	images      *ImageProcessor
+	cfg         config.AttachmentConfig
+}
+
+// AttachmentContent — открытое для чтения содержимое вложения или его копии
+type AttachmentContent struct {
+	Filename    string
+	ContentType string
+	Size        int64
+	Content     io.ReadCloser
+}
+
+func NewAttachmentService(
+	repo *repository.AttachmentRepository,
+	postRepo *repository.PostRepository,
+	commentRepo *repository.CommentRepository,
+	store storage.BlobStore,
+	images *ImageProcessor,
+	cfg config.AttachmentConfig,
+) *AttachmentService {
+	return &AttachmentService{
+		repo:        repo,
+		postRepo:    postRepo,
+		commentRepo: commentRepo,
+		store:       store,
+		images:      images,
+		cfg:         cfg,
+	}
+}
+
+// MaxSize возвращает максимальный размер загружаемого файла
+func (s *AttachmentService) MaxSize() int64 {
+	return s.cfg.MaxSize
+}
+
+// Upload прикрепляет файл к посту или, если commentID не равен 0,
+// к комментарию. Прикреплять файлы может автор поста или комментария.
+// Тип файла определяется по содержимому. Изображения обрабатываются в фоне
+// и до окончания обработки доступны только загрузившему их пользователю.
+func (s *AttachmentService) Upload(ctx context.Context, postID, commentID, userID int64, asModerator bool, upload Upload) (*model.Attachment, error) {
+	if upload.Size > s.cfg.MaxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	if err := s.ensureOwner(postID, commentID, userID, asModerator); err != nil {
+		return nil, err
+	}
+
+	// Предварительная проверка избавляет от загрузки заведомо лишнего файла
+	// в хранилище. Окончательно квота проверяется при сохранении вложения.
+	used, err := s.repo.UsedBytes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if used+upload.Size > s.cfg.UserQuota {
+		return nil, ErrQuotaExceeded
+	}
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(upload.Content, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	head = head[:n]
+
+	mtype := mimetype.Detect(head)
+	if !s.allowed(mtype) {
+		return nil, newValidationError(map[string]string{
+			"file": "file type " + mtype.String() + " is not allowed",
+		})
+	}
+
+	attachment := &model.Attachment{
+		UserID:      userID,
+		PostID:      &postID,
+		StorageKey:  newStorageKey(mtype.Extension()),
+		Filename:    sanitizeFilename(upload.Filename),
+		ContentType: mtype.String(),
+		Size:        upload.Size,
+		Processing:  model.AttachmentProcessingNone,
+	}
+	if commentID != 0 {
+		attachment.CommentID = &commentID
+	}
+	if processableImageTypes[attachment.ContentType] {
+		attachment.Processing = model.AttachmentProcessingPending
+	}
+
+	content := io.MultiReader(bytes.NewReader(head), upload.Content)
+	if err := s.store.Put(ctx, attachment.StorageKey, content, upload.Size, attachment.ContentType); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.Create(attachment, s.cfg.UserQuota)
+	if err != nil || !created {
+		// Содержимое без метаданных никогда не будет прочитано
+		_ = s.store.Delete(context.WithoutCancel(ctx), attachment.StorageKey)
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrQuotaExceeded
+	}
+	if attachment.Processing == model.AttachmentProcessingPending {
+		s.images.Notify()
+	}
+	setAttachmentURL(attachment)
+	return attachment, nil
+}
+
+// GetByPostID возвращает вложения поста
+func (s *AttachmentService) GetByPostID(postID, viewerID int64) ([]*model.Attachment, error) {
+	if err := s.ensureVisible(postID, viewerID); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.repo.GetByPostID(postID)
+	if err != nil {
+		return nil, err
+	}
+	for _, attachment := range attachments {
+		setAttachmentURL(attachment)
+	}
+	return attachments, nil
+}
+
+// GetByCommentID возвращает вложения комментария
+func (s *AttachmentService) GetByCommentID(postID, commentID, viewerID int64) ([]*model.Attachment, error) {
+	if err := s.ensureVisible(postID, viewerID); err != nil {
+		return nil, err
+	}
+	if err := s.ensureComment(postID, commentID); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.repo.GetByCommentID(commentID)
+	if err != nil {
+		return nil, err
+	}
+	for _, attachment := range attachments {
+		setAttachmentURL(attachment)
+	}
+	return attachments, nil
+}
+
+// Open открывает для чтения содержимое вложения или, если size не пуст,
+// его уменьшенной копии. Если копии нет, открывается само вложение.
+// Вызывающий должен закрыть содержимое.
+func (s *AttachmentService) Open(ctx context.Context, id, viewerID int64, size string) (*AttachmentContent, error) {
+	if size != "" && size != "original" && !imageSizeExists(size) {
+		return nil, newValidationError(map[string]string{
+			"size": "unknown size " + size,
+		})
+	}
+
+	attachment, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.UserID != viewerID {
+		// Необработанные изображения могут содержать координаты съемки
+		if attachment.Processing != model.AttachmentProcessingNone && attachment.Processing != model.AttachmentProcessingDone {
+			return nil, ErrAttachmentNotFound
+		}
+		if err := s.ensureVisible(*attachment.PostID, viewerID); err != nil {
+			return nil, ErrAttachmentNotFound
+		}
+	}
+
+	result := &AttachmentContent{
+		Filename:    attachment.Filename,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+	}
+	key := attachment.StorageKey
+	for _, variant := range attachment.Variants {
+		if variant.Name == size {
+			key = variant.StorageKey
+			result.ContentType = variant.ContentType
+			result.Size = variant.Size
+			if variant.ContentType != attachment.ContentType {
+				result.Filename = strings.TrimSuffix(attachment.Filename, path.Ext(attachment.Filename)) + imageExtension(variant.ContentType)
+			}
+		}
+	}
+
+	result.Content, err = s.store.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// Delete открепляет вложение. Удалить вложение может загрузивший его
+// пользователь или модератор.
+func (s *AttachmentService) Delete(id, userID int64, asModerator bool) error {
+	attachment, err := s.get(id)
+	if err != nil {
+		return err
+	}
+	if attachment.UserID != userID && !asModerator {
+		return ErrAttachmentNotFound
+	}
+
+	if err := s.repo.Detach(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAttachmentNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *AttachmentService) get(id int64) (*model.Attachment, error) {
+	attachment, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	setAttachmentURL(attachment)
+	return attachment, nil
+}
+
+// ensureOwner проверяет, что пользователь может прикреплять файлы к посту
+// или комментарию. В закрытой теме это доступно только модераторам.
+func (s *AttachmentService) ensureOwner(postID, commentID, userID int64, asModerator bool) error {
+	post, err := s.postRepo.GetByIDAnyStatus(postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPostNotFound
+		}
+		return err
+	}
+	if post.Locked && !asModerator {
+		return ErrPostLocked
+	}
+
+	if commentID == 0 {
+		if post.AuthorID != userID && !asModerator {
+			return ErrAttachmentForbidden
+		}
+		return nil
+	}
+
+	if post.Status != model.PostStatusPublished {
+		return ErrPostNotFound
+	}
+	comment, err := s.commentRepo.GetByID(commentID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if comment == nil || comment.PostID != postID {
+		return ErrCommentNotFound
+	}
+	if comment.AuthorID != userID && !asModerator {
+		return ErrAttachmentForbidden
+	}
+	return nil
+}
+
+// ensureVisible проверяет, что пост опубликован или принадлежит viewerID
+func (s *AttachmentService) ensureVisible(postID, viewerID int64) error {
+	post, err := s.postRepo.GetByIDAnyStatus(postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPostNotFound
+		}
+		return err
+	}
+	if post.Status != model.PostStatusPublished && post.AuthorID != viewerID {
+		return ErrPostNotFound
+	}
+	return nil
+}
+
+func (s *AttachmentService) ensureComment(postID, commentID int64) error {
+	comment, err := s.commentRepo.GetByID(commentID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if comment == nil || comment.PostID != postID {
+		return ErrCommentNotFound
+	}
+	return nil
+}
+
+func (s *AttachmentService) allowed(mtype *mimetype.MIME) bool {
+	for _, allowed := range s.cfg.AllowedTypes {
+		if mtype.Is(allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// newStorageKey возвращает случайный ключ объекта, сгруппированный по месяцам
+func newStorageKey(ext string) string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return "attachments/" + time.Now().UTC().Format("2006/01") + "/" + hex.EncodeToString(b[:]) + ext
+}
+
+// sanitizeFilename оставляет от имени файла, переданного клиентом, только
+// последний компонент пути без управляющих символов
+func sanitizeFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	for utf8.RuneCountInString(name) > maxFilenameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
+
+func imageSizeExists(name string) bool {
+	for _, size := range imaging.Sizes {
+		if size.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func setAttachmentURL(attachment *model.Attachment) {
+	attachment.URL = "/api/attachments/" + strconv.FormatInt(attachment.ID, 10)
+	for _, variant := range attachment.Variants {
+		variant.URL = attachment.URL + "?size=" + variant.Name
+	}
+}
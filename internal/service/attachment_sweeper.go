@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/repository"
+	"github.com/fire9900/golang-forum/internal/storage"
+)
+
+// sweepBatchSize ограничивает число вложений, удаляемых за один проход
+const sweepBatchSize = 100
+
+// AttachmentSweeper периодически удаляет из хранилища содержимое
+// открепленных вложений и их метаданные
+type AttachmentSweeper struct {
+	repo     *repository.AttachmentRepository
+	store    storage.BlobStore
+	interval time.Duration
+}
+
+func NewAttachmentSweeper(repo *repository.AttachmentRepository, store storage.BlobStore, interval time.Duration) *AttachmentSweeper {
+	return &AttachmentSweeper{repo: repo, store: store, interval: interval}
+}
+
+// Run удаляет открепленные вложения до отмены ctx
+func (s *AttachmentSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.sweep(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *AttachmentSweeper) sweep(ctx context.Context) {
+	total := 0
+	for ctx.Err() == nil {
+		attachments, err := s.repo.GetDetached(imageProcessingTimeout, sweepBatchSize)
+		if err != nil {
+			log.Printf("Ошибка поиска открепленных вложений: %s\n", err.Error())
+			return
+		}
+
+		for _, attachment := range attachments {
+			// Метаданные удаляются только после содержимого, поэтому при
+			// ошибке попытка повторится на следующем проходе
+			if err := s.deleteContent(ctx, attachment); err != nil {
+				log.Printf("Ошибка удаления вложения %d: %s\n", attachment.ID, err.Error())
+				return
+			}
+			if err := s.repo.Delete(attachment.ID); err != nil {
+				log.Printf("Ошибка удаления вложения %d: %s\n", attachment.ID, err.Error())
+				return
+			}
+			total++
+		}
+
+		if len(attachments) < sweepBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Printf("Удалено открепленных вложений: %d\n", total)
+	}
+}
+
+// deleteContent удаляет из хранилища вложение вместе с его копиями
+func (s *AttachmentSweeper) deleteContent(ctx context.Context, attachment *model.Attachment) error {
+	for _, variant := range attachment.Variants {
+		if err := s.store.Delete(ctx, variant.StorageKey); err != nil {
+			return err
+		}
+	}
+	return s.store.Delete(ctx, attachment.StorageKey)
+}
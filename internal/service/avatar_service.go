@@ -0,0 +1,272 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"strconv"
+	"sync"
+
+	"github.com/fire9900/golang-forum/internal/config"
+	"github.com/fire9900/golang-forum/internal/imaging"
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/repository"
+	"github.com/fire9900/golang-forum/internal/storage"
+)
+
+// avatarSides — стороны хранимых копий аватара в пикселях
+var avatarSides = []int{64, 128, 256}
+
+const (
+	// defaultAvatarSide — сторона аватара, если размер не указан
+	defaultAvatarSide = 128
+	// maxCachedIdenticons ограничивает число идентиконов в памяти
+	maxCachedIdenticons = 10000
+)
+
+type AvatarService struct {
+	repo       *repository.AvatarRepository
+	users      *repository.UserRepository
+	store      storage.BlobStore
+	cfg        config.AvatarConfig
+	identicons *identiconCache
+}
+
+// AvatarContent — открытое для чтения изображение аватара
+type AvatarContent struct {
+	ContentType string
+	Size        int64
+	Content     io.ReadCloser
+}
+
+func NewAvatarService(
+	repo *repository.AvatarRepository,
+	users *repository.UserRepository,
+	store storage.BlobStore,
+	cfg config.AvatarConfig,
+) *AvatarService {
+	return &AvatarService{
+		repo:       repo,
+		users:      users,
+		store:      store,
+		cfg:        cfg,
+		identicons: newIdenticonCache(maxCachedIdenticons),
+	}
+}
+
+// MaxSize возвращает максимальный размер загружаемого аватара
+func (s *AvatarService) MaxSize() int64 {
+	return s.cfg.MaxSize
+}
+
+// Upload заменяет аватар пользователя. Изображение обрезается до квадрата
+// по центру и сохраняется во всех размерах без метаданных.
+func (s *AvatarService) Upload(ctx context.Context, userID int64, upload Upload) error {
+	if upload.Size > s.cfg.MaxSize {
+		return ErrAvatarTooLarge
+	}
+
+	data, err := io.ReadAll(io.LimitReader(upload.Content, s.cfg.MaxSize+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > s.cfg.MaxSize {
+		return ErrAvatarTooLarge
+	}
+
+	images, err := imaging.Avatar(data, avatarSides)
+	if err != nil {
+		if errors.Is(err, imaging.ErrTooLarge) {
+			return newValidationError(map[string]string{"file": "image dimensions are too large"})
+		}
+		return newValidationError(map[string]string{"file": "must be a JPEG, PNG or GIF image"})
+	}
+
+	keyBase := newAvatarKeyBase(userID)
+	variants := make([]*model.AvatarVariant, 0, len(images))
+	for i, img := range images {
+		variant := &model.AvatarVariant{
+			Side:        avatarSides[i],
+			StorageKey:  keyBase + "-" + strconv.Itoa(avatarSides[i]) + imageExtension(img.ContentType),
+			ContentType: img.ContentType,
+			Size:        int64(len(img.Data)),
+		}
+		if err := s.store.Put(ctx, variant.StorageKey, bytes.NewReader(img.Data), variant.Size, variant.ContentType); err != nil {
+			s.deleteBlobs(context.WithoutCancel(ctx), avatarKeys(variants))
+			return err
+		}
+		variants = append(variants, variant)
+	}
+
+	old, err := s.repo.Replace(userID, variants)
+	if err != nil {
+		s.deleteBlobs(context.WithoutCancel(ctx), avatarKeys(variants))
+		return err
+	}
+	s.deleteBlobs(context.WithoutCancel(ctx), old)
+	return nil
+}
+
+// Delete удаляет загруженный аватар пользователя, после чего ему снова
+// показывается идентикон
+func (s *AvatarService) Delete(ctx context.Context, userID int64) error {
+	keys, err := s.repo.Delete(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAvatarNotFound
+		}
+		return err
+	}
+	s.deleteBlobs(context.WithoutCancel(ctx), keys)
+	return nil
+}
+
+// Open открывает для чтения аватар пользователя со стороной size. Если
+// пользователь не загружал аватар, возвращается идентикон, построенный по
+// его идентификатору, а для пользователей не из каталога — ErrUserNotFound.
+// Вызывающий должен закрыть содержимое.
+func (s *AvatarService) Open(ctx context.Context, userID int64, size string) (*AvatarContent, error) {
+	side := defaultAvatarSide
+	if size != "" {
+		var err error
+		side, err = strconv.Atoi(size)
+		if err != nil || !avatarSideExists(side) {
+			return nil, newValidationError(map[string]string{
+				"size": "size must be one of 64, 128, 256",
+			})
+		}
+	}
+
+	variant, err := s.repo.Get(userID, side)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if variant != nil {
+		content, err := s.store.Get(ctx, variant.StorageKey)
+		if err == nil {
+			return &AvatarContent{
+				ContentType: variant.ContentType,
+				Size:        variant.Size,
+				Content:     content,
+			}, nil
+		}
+		if !errors.Is(err, storage.ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	exists, err := s.users.Exists(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists && variant == nil {
+		return nil, ErrUserNotFound
+	}
+	return s.identicon(userID, side)
+}
+
+// identicon возвращает идентикон пользователя, рисуя его только при первом
+// обращении
+func (s *AvatarService) identicon(userID int64, side int) (*AvatarContent, error) {
+	key := identiconKey{userID: userID, side: side}
+	img, ok := s.identicons.get(key)
+	if !ok {
+		var err error
+		if img, err = imaging.Identicon([]byte(strconv.FormatInt(userID, 10)), side); err != nil {
+			return nil, err
+		}
+		s.identicons.add(key, img)
+	}
+	return &AvatarContent{
+		ContentType: img.ContentType,
+		Size:        int64(len(img.Data)),
+		Content:     io.NopCloser(bytes.NewReader(img.Data)),
+	}, nil
+}
+
+// deleteBlobs удаляет копии аватара из хранилища. Ошибки только
+// записываются в журнал: строки о копиях к этому моменту уже удалены.
+func (s *AvatarService) deleteBlobs(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			log.Printf("Ошибка удаления аватара %s: %s\n", key, err.Error())
+		}
+	}
+}
+
+func avatarSideExists(side int) bool {
+	for _, s := range avatarSides {
+		if s == side {
+			return true
+		}
+	}
+	return false
+}
+
+func avatarKeys(variants []*model.AvatarVariant) []string {
+	keys := make([]string, len(variants))
+	for i, variant := range variants {
+		keys[i] = variant.StorageKey
+	}
+	return keys
+}
+
+// newAvatarKeyBase создает случайный префикс ключей хранилища для копий
+// нового аватара. Ключ меняется при каждой загрузке, чтобы старые копии
+// не оставались в кэше под прежним адресом.
+func newAvatarKeyBase(userID int64) string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return "avatars/" + strconv.FormatInt(userID, 10) + "/" + hex.EncodeToString(b[:])
+}
+
+// avatarURL возвращает адрес аватара пользователя
+func avatarURL(userID int64) string {
+	return "/api/users/" + strconv.FormatInt(userID, 10) + "/avatar"
+}
+
+type identiconKey struct {
+	userID int64
+	side   int
+}
+
+// identiconCache хранит нарисованные идентиконы. При переполнении
+// вытесняются добавленные раньше других.
+type identiconCache struct {
+	limit int
+
+	mu     sync.Mutex
+	images map[identiconKey]*imaging.Image
+	order  []identiconKey
+}
+
+func newIdenticonCache(limit int) *identiconCache {
+	return &identiconCache{limit: limit, images: make(map[identiconKey]*imaging.Image)}
+}
+
+func (c *identiconCache) get(key identiconKey) (*imaging.Image, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	img, ok := c.images[key]
+	return img, ok
+}
+
+func (c *identiconCache) add(key identiconKey, img *imaging.Image) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.images[key]; ok {
+		return
+	}
+	if len(c.order) >= c.limit {
+		delete(c.images, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.images[key] = img
+	c.order = append(c.order, key)
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/pagination"
+	"github.com/fire9900/golang-forum/internal/repository"
+)
+
+const (
+	maxBookmarkFolderLength = 100
+	maxBookmarkNoteLength   = 2000
+)
+
+type BookmarkService struct {
+	repo     *repository.BookmarkRepository
+	postRepo *repository.PostRepository
+}
+
+func NewBookmarkService(repo *repository.BookmarkRepository, postRepo *repository.PostRepository) *BookmarkService {
+	return &BookmarkService{repo: repo, postRepo: postRepo}
+}
+
+// Save добавляет опубликованный пост в закладки пользователя. Для уже
+// сохраненного поста обновляются папка и заметка.
+func (s *BookmarkService) Save(userID, postID int64, req model.BookmarkRequest) (*model.Bookmark, error) {
+	bookmark := &model.Bookmark{
+		PostID: postID,
+		Folder: strings.TrimSpace(req.Folder),
+		Note:   strings.TrimSpace(req.Note),
+	}
+
+	details := map[string]string{}
+	if utf8.RuneCountInString(bookmark.Folder) > maxBookmarkFolderLength {
+		details["folder"] = fmt.Sprintf("must be at most %d characters", maxBookmarkFolderLength)
+	}
+	if utf8.RuneCountInString(bookmark.Note) > maxBookmarkNoteLength {
+		details["note"] = fmt.Sprintf("must be at most %d characters", maxBookmarkNoteLength)
+	}
+	if err := newValidationError(details); err != nil {
+		return nil, err
+	}
+
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPostNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.repo.Save(userID, bookmark); err != nil {
+		return nil, err
+	}
+	bookmarked := true
+	post.Bookmarked = &bookmarked
+	bookmark.Post = post
+	return bookmark, nil
+}
+
+// Delete удаляет пост из закладок пользователя
+func (s *BookmarkService) Delete(userID, postID int64) error {
+	if err := s.repo.Delete(userID, postID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrBookmarkNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// GetByUser возвращает страницу закладок пользователя вместе с постами.
+// Если folder не nil, выбираются только закладки из этой папки.
+func (s *BookmarkService) GetByUser(userID int64, folder *string, req pagination.Request) (pagination.Page[*model.Bookmark], error) {
+	details := map[string]string{}
+	validatePageRequest(req, details)
+	if err := newValidationError(details); err != nil {
+		return pagination.Page[*model.Bookmark]{}, err
+	}
+
+	if folder != nil {
+		trimmed := strings.TrimSpace(*folder)
+		folder = &trimmed
+	}
+
+	page, err := s.repo.GetByUser(userID, folder, req)
+	if err != nil {
+		return pagination.Page[*model.Bookmark]{}, err
+	}
+
+	ids := make([]int64, len(page.Items))
+	for i, bookmark := range page.Items {
+		ids[i] = bookmark.PostID
+	}
+	posts, err := s.postRepo.GetByIDs(ids)
+	if err != nil {
+		return pagination.Page[*model.Bookmark]{}, err
+	}
+
+	byID := make(map[int64]*model.Post, len(posts))
+	for _, post := range posts {
+		bookmarked := true
+		post.Bookmarked = &bookmarked
+		byID[post.ID] = post
+	}
+	for _, bookmark := range page.Items {
+		bookmark.Post = byID[bookmark.PostID]
+	}
+	return page, nil
+}
+
+// GetFolders возвращает папки закладок пользователя
+func (s *BookmarkService) GetFolders(userID int64) ([]*model.BookmarkFolder, error) {
+	return s.repo.GetFolders(userID)
+}
+
+// MarkBookmarked отмечает посты, находящиеся в закладках пользователя
+func (s *BookmarkService) MarkBookmarked(userID int64, posts []*model.Post) error {
+	ids := make([]int64, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+
+	bookmarked, err := s.repo.Bookmarked(userID, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		marked := bookmarked[post.ID]
+		post.Bookmarked = &marked
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/fire9900/golang-forum/internal/markdown"
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/repository"
+)
+
+const (
+	defaultCommentsPerPage = 20
+	maxCommentsPerPage     = 100
+)
+
+type CommentService struct {
+	repo     *repository.CommentRepository
+	postRepo *repository.PostRepository
+	mentions *MentionService
+}
+
+func NewCommentService(repo *repository.CommentRepository, postRepo *repository.PostRepository, mentions *MentionService) *CommentService {
+	return &CommentService{repo: repo, postRepo: postRepo, mentions: mentions}
+}
+
+// Create добавляет комментарий к посту. В закрытую тему могут писать
+// только модераторы.
+func (s *CommentService) Create(comment *model.Comment, asModerator bool) error {
+	if err := s.ensureWritable(comment.PostID, asModerator); err != nil {
+		return err
+	}
+
+	if comment.ParentID != nil {
+		parent, err := s.repo.GetByID(*comment.ParentID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if parent == nil || parent.PostID != comment.PostID {
+			return ErrParentNotFound
+		}
+	}
+
+	if err := renderComment(comment); err != nil {
+		return err
+	}
+	if err := s.repo.Create(comment); err != nil {
+		return err
+	}
+	s.mentions.Sync(comment.PostID, comment.ID, comment.AuthorID, comment.Content)
+
+	created, err := s.repo.GetByID(comment.ID)
+	if err != nil {
+		return err
+	}
+	*comment = *created
+	return nil
+}
+
+func (s *CommentService) GetByPostID(postID int64, page, perPage int) ([]*model.Comment, error) {
+	if err := s.ensurePost(postID); err != nil {
+		return nil, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = defaultCommentsPerPage
+	}
+	if perPage > maxCommentsPerPage {
+		perPage = maxCommentsPerPage
+	}
+
+	offset := (page - 1) * perPage
+	return s.repo.GetByPostID(postID, perPage, offset)
+}
+
+func (s *CommentService) Update(comment *model.Comment) error {
+	if err := s.ensureWritable(comment.PostID, false); err != nil {
+		return err
+	}
+
+	if err := renderComment(comment); err != nil {
+		return err
+	}
+	if err := s.repo.Update(comment); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCommentNotFound
+		}
+		return err
+	}
+	s.mentions.Sync(comment.PostID, comment.ID, comment.AuthorID, comment.Content)
+
+	updated, err := s.repo.GetByID(comment.ID)
+	if err != nil {
+		return err
+	}
+	*comment = *updated
+	return nil
+}
+
+func (s *CommentService) Delete(id, postID, authorID int64) error {
+	if err := s.repo.Delete(id, postID, authorID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCommentNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// ensurePost проверяет, что пост, к которому относится комментарий, существует
+func (s *CommentService) ensurePost(postID int64) error {
+	_, err := s.getPost(postID)
+	return err
+}
+
+// ensureWritable проверяет, что в теме можно оставлять и править комментарии
+func (s *CommentService) ensureWritable(postID int64, asModerator bool) error {
+	post, err := s.getPost(postID)
+	if err != nil {
+		return err
+	}
+	if post.Locked && !asModerator {
+		return ErrPostLocked
+	}
+	return nil
+}
+
+func (s *CommentService) getPost(postID int64) (*model.Post, error) {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPostNotFound
+		}
+		return nil, err
+	}
+	return post, nil
+}
+
+// renderComment заполняет HTML комментария по его тексту
+func renderComment(comment *model.Comment) error {
+	html, err := markdown.Render(comment.Content)
+	if err != nil {
+		return err
+	}
+	comment.ContentHTML = html
+	return nil
+}
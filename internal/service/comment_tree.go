@@ -0,0 +1,153 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/fire9900/golang-forum/internal/model"
+)
+
+const (
+	defaultTreeDepth = 5
+	maxTreeDepth     = 10
+	defaultTreeLimit = 20
+	maxTreeLimit     = 100
+)
+
+// TreeOptions задает параметры выборки дерева комментариев
+type TreeOptions struct {
+	Depth  int
+	Limit  int
+	Cursor string
+}
+
+// treeCursor указывает, с какого места продолжать вывод ответов ветки
+type treeCursor struct {
+	parentID int64
+	afterID  int64
+}
+
+func (c treeCursor) encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", c.parentID, c.afterID)))
+}
+
+func decodeTreeCursor(s string) (treeCursor, error) {
+	var c treeCursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &c.parentID, &c.afterID); err != nil {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// GetTree возвращает комментарии поста в виде дерева с ограничением глубины
+// и количества ответов в каждой ветке
+func (s *CommentService) GetTree(postID int64, opts TreeOptions) (*model.CommentTree, error) {
+	if err := s.ensurePost(postID); err != nil {
+		return nil, err
+	}
+
+	if opts.Depth < 1 {
+		opts.Depth = defaultTreeDepth
+	}
+	if opts.Depth > maxTreeDepth {
+		opts.Depth = maxTreeDepth
+	}
+	if opts.Limit < 1 {
+		opts.Limit = defaultTreeLimit
+	}
+	if opts.Limit > maxTreeLimit {
+		opts.Limit = maxTreeLimit
+	}
+
+	var cursor treeCursor
+	if opts.Cursor != "" {
+		var err error
+		if cursor, err = decodeTreeCursor(opts.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	comments, err := s.repo.GetAllByPostID(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[int64][]*model.Comment)
+	for _, comment := range comments {
+		var parentID int64
+		if comment.ParentID != nil {
+			parentID = *comment.ParentID
+		}
+		children[parentID] = append(children[parentID], comment)
+	}
+
+	b := treeBuilder{children: children, depth: opts.Depth, limit: opts.Limit}
+	nodes, next, err := b.level(cursor.parentID, cursor.afterID, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CommentTree{Comments: nodes, NextCursor: next}, nil
+}
+
+type treeBuilder struct {
+	children map[int64][]*model.Comment
+	depth    int
+	limit    int
+}
+
+// level строит один уровень дерева: ответы parentID, начиная после afterID
+func (b treeBuilder) level(parentID, afterID int64, depth int) ([]*model.CommentNode, string, error) {
+	siblings := b.children[parentID]
+
+	start := 0
+	if afterID != 0 {
+		start = -1
+		for i, comment := range siblings {
+			if comment.ID == afterID {
+				start = i + 1
+				break
+			}
+		}
+		if start < 0 {
+			return nil, "", ErrInvalidCursor
+		}
+	}
+
+	end := min(start+b.limit, len(siblings))
+
+	nodes := make([]*model.CommentNode, 0, end-start)
+	for _, comment := range siblings[start:end] {
+		node := &model.CommentNode{
+			Comment:    *comment,
+			Replies:    []*model.CommentNode{},
+			ReplyCount: len(b.children[comment.ID]),
+		}
+
+		if node.ReplyCount > 0 {
+			if depth < b.depth {
+				replies, more, err := b.level(comment.ID, 0, depth+1)
+				if err != nil {
+					return nil, "", err
+				}
+				node.Replies = replies
+				node.MoreCursor = more
+			} else {
+				node.MoreCursor = treeCursor{parentID: comment.ID}.encode()
+			}
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	var next string
+	if end < len(siblings) {
+		next = treeCursor{parentID: parentID, afterID: siblings[end-1].ID}.encode()
+	}
+
+	return nodes, next, nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"github.com/fire9900/golang-forum/internal/markdown"
+	"github.com/fire9900/golang-forum/internal/repository"
+)
+
+// renderBatchSize ограничивает число записей, отрисовываемых за один запрос
+const renderBatchSize = 100
+
+// contentStore — хранилище текстов, HTML которых заполняется в фоне
+type contentStore interface {
+	GetUnrendered(limit int) (map[int64]string, error)
+	SetContentHTML(id int64, html string) error
+}
+
+// ContentRenderer отрисовывает HTML постов и комментариев, сохраненных до
+// появления отрисовки. Новые тексты отрисовываются при записи, поэтому
+// задача завершается, когда необработанных записей не остается.
+type ContentRenderer struct {
+	stores map[string]contentStore
+}
+
+func NewContentRenderer(postRepo *repository.PostRepository, commentRepo *repository.CommentRepository) *ContentRenderer {
+	return &ContentRenderer{stores: map[string]contentStore{"posts": postRepo, "comments": commentRepo}}
+}
+
+// Run отрисовывает оставшиеся тексты или прекращает работу при отмене ctx
+func (r *ContentRenderer) Run(ctx context.Context) {
+	for name, store := range r.stores {
+		total, err := r.render(ctx, store)
+		if err != nil {
+			log.Printf("Ошибка отрисовки текста (%s): %s\n", name, err.Error())
+		}
+		if total > 0 {
+			log.Printf("Отрисовано текстов (%s): %d\n", name, total)
+		}
+	}
+}
+
+func (r *ContentRenderer) render(ctx context.Context, store contentStore) (int, error) {
+	total := 0
+	for ctx.Err() == nil {
+		contents, err := store.GetUnrendered(renderBatchSize)
+		if err != nil || len(contents) == 0 {
+			return total, err
+		}
+
+		for id, content := range contents {
+			html, err := markdown.Render(content)
+			if err != nil {
+				return total, err
+			}
+			if err := store.SetContentHTML(id, html); err != nil {
+				return total, err
+			}
+			total++
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import "errors"
+
+var (
+	ErrPostNotFound         = errors.New("post not found")
+	ErrCommentNotFound      = errors.New("comment not found")
+	ErrParentNotFound       = errors.New("parent comment not found in this post")
+	ErrInvalidCursor        = errors.New("invalid cursor")
+	ErrCategoryNotFound     = errors.New("category not found")
+	ErrInvalidCategory      = errors.New("category_id does not reference an existing category")
+	ErrRevisionNotFound     = errors.New("revision not found")
+	ErrPostLocked           = errors.New("post is locked")
+	ErrBookmarkNotFound     = errors.New("bookmark not found")
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+	ErrPollNotFound         = errors.New("poll not found")
+	ErrPollExists           = errors.New("post already has a poll")
+	ErrPollClosed           = errors.New("poll is closed")
+	ErrPollForbidden        = errors.New("only the post author can manage its poll")
+	ErrAlreadyVoted         = errors.New("already voted in this poll")
+	ErrPollResultsHidden    = errors.New("poll results are hidden until voting ends")
+	ErrAttachmentNotFound   = errors.New("attachment not found")
+	ErrAttachmentForbidden  = errors.New("only the author can attach files")
+	ErrAttachmentTooLarge   = errors.New("file is too large")
+	ErrQuotaExceeded        = errors.New("attachment storage quota exceeded")
+	ErrUserNotFound         = errors.New("user not found")
+	ErrAvatarNotFound       = errors.New("avatar not found")
+	ErrAvatarTooLarge       = errors.New("avatar image is too large")
+)
+
+// ValidationError описывает ошибки валидации входных данных по полям
+type ValidationError struct {
+	Details map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	return "validation failed"
+}
+
+// newValidationError возвращает ошибку валидации, если набор ошибок не пуст
+func newValidationError(details map[string]string) error {
+	if len(details) == 0 {
+		return nil
+	}
+	return &ValidationError{Details: details}
+}
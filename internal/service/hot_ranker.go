@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/fire9900/golang-forum/internal/repository"
+)
+
+// HotRanker периодически пересчитывает рейтинг "hot", который убывает с
+// возрастом поста даже без новых голосов
+type HotRanker struct {
+	repo     *repository.VoteRepository
+	interval time.Duration
+	window   time.Duration
+}
+
+func NewHotRanker(repo *repository.VoteRepository, interval, window time.Duration) *HotRanker {
+	return &HotRanker{repo: repo, interval: interval, window: window}
+}
+
+// Run пересчитывает рейтинг до отмены ctx
+func (r *HotRanker) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.repo.RefreshHotScores(r.window); err != nil {
+			log.Printf("Ошибка пересчета рейтинга постов: %s\n", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/fire9900/golang-forum/internal/imaging"
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/repository"
+	"github.com/fire9900/golang-forum/internal/storage"
+)
+
+// imageProcessingTimeout — срок, после которого незавершенная обработка
+// изображения считается прерванной и выполняется заново
+const imageProcessingTimeout = 10 * time.Minute
+
+// processableImageTypes — типы изображений, которые умеет обрабатывать imaging
+var processableImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// errImageRejected отмечает изображения, которые невозможно обработать.
+// Такие вложения помечаются как необработанные без повторных попыток.
+var errImageRejected = errors.New("image rejected")
+
+// ImageProcessor в фоне создает уменьшенные копии загруженных изображений
+// и удаляет из них метаданные, в том числе координаты съемки. Может
+// одновременно работать в нескольких экземплярах приложения.
+type ImageProcessor struct {
+	repo     *repository.AttachmentRepository
+	store    storage.BlobStore
+	interval time.Duration
+	wake     chan struct{}
+}
+
+func NewImageProcessor(repo *repository.AttachmentRepository, store storage.BlobStore, interval time.Duration) *ImageProcessor {
+	return &ImageProcessor{repo: repo, store: store, interval: interval, wake: make(chan struct{}, 1)}
+}
+
+// Notify сообщает о загрузке изображения, чтобы обработка началась, не
+// дожидаясь очередной проверки
+func (p *ImageProcessor) Notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run обрабатывает изображения до отмены ctx
+func (p *ImageProcessor) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.processPending(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-p.wake:
+		}
+	}
+}
+
+func (p *ImageProcessor) processPending(ctx context.Context) {
+	for ctx.Err() == nil {
+		attachment, err := p.repo.ClaimPending(imageProcessingTimeout)
+		if errors.Is(err, sql.ErrNoRows) {
+			return
+		}
+		if err != nil {
+			log.Printf("Ошибка выбора изображений для обработки: %s\n", err.Error())
+			return
+		}
+
+		err = p.process(ctx, attachment)
+		if err == nil {
+			continue
+		}
+		log.Printf("Ошибка обработки изображения %d: %s\n", attachment.ID, err.Error())
+
+		// Прочие ошибки временные: обработка повторится после таймаута
+		if errors.Is(err, errImageRejected) {
+			if err := p.repo.FailProcessing(attachment.ID); err != nil {
+				log.Printf("Ошибка обработки изображения %d: %s\n", attachment.ID, err.Error())
+			}
+		}
+	}
+}
+
+func (p *ImageProcessor) process(ctx context.Context, attachment *model.Attachment) error {
+	content, err := p.store.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(content)
+	content.Close()
+	if err != nil {
+		return err
+	}
+
+	result, err := imaging.Process(data)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errImageRejected, err)
+	}
+
+	base := strings.TrimSuffix(attachment.StorageKey, path.Ext(attachment.StorageKey))
+
+	var original *model.AttachmentVariant
+	if result.Original != nil {
+		original, err = p.put(ctx, base+"-clean", "original", result.Original)
+		if err != nil {
+			return err
+		}
+	}
+
+	variants := make([]*model.AttachmentVariant, 0, len(imaging.Sizes))
+	for _, size := range imaging.Sizes {
+		variant, err := p.put(ctx, base+"-"+size.Name, size.Name, result.Variants[size.Name])
+		if err != nil {
+			return err
+		}
+		variants = append(variants, variant)
+	}
+
+	attachment.Width, attachment.Height = &result.Width, &result.Height
+	if err := p.repo.CompleteProcessing(attachment, original, variants); err != nil {
+		return err
+	}
+
+	// Исходный файл с метаданными больше не нужен
+	if original != nil {
+		if err := p.store.Delete(ctx, attachment.StorageKey); err != nil {
+			log.Printf("Ошибка удаления исходного изображения %d: %s\n", attachment.ID, err.Error())
+		}
+	}
+	return nil
+}
+
+// put сохраняет изображение в хранилище под ключом keyBase с расширением,
+// соответствующим его типу
+func (p *ImageProcessor) put(ctx context.Context, keyBase, name string, img *imaging.Image) (*model.AttachmentVariant, error) {
+	variant := &model.AttachmentVariant{
+		Name:        name,
+		StorageKey:  keyBase + imageExtension(img.ContentType),
+		ContentType: img.ContentType,
+		Size:        int64(len(img.Data)),
+		Width:       img.Width,
+		Height:      img.Height,
+	}
+	if err := p.store.Put(ctx, variant.StorageKey, bytes.NewReader(img.Data), variant.Size, variant.ContentType); err != nil {
+		return nil, err
+	}
+	return variant, nil
+}
+
+// imageExtension возвращает расширение файла для типа обработанного изображения
+func imageExtension(contentType string) string {
+	if contentType == "image/jpeg" {
+		return ".jpg"
+	}
+	return ".png"
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/pagination"
+	"github.com/fire9900/golang-forum/internal/repository"
+)
+
+// maxMentions ограничивает число упоминаний, учитываемых в одном тексте
+const maxMentions = 20
+
+var (
+	// mentionPattern находит @имя, перед которым нет буквы или цифры, чтобы
+	// не принимать за упоминания адреса электронной почты
+	mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_@.])@([\p{L}\p{N}_.\-]{3,50})`)
+	// codePattern находит блоки и фрагменты кода, в которых упоминаний нет
+	codePattern = regexp.MustCompile("(?s)```.*?(```|$)|~~~.*?(~~~|$)|`[^`\n]*`")
+)
+
+// UserDirectory сопоставляет имена пользователей с их идентификаторами
+type UserDirectory interface {
+	// LookupUsernames возвращает идентификаторы найденных пользователей.
+	// Ключи результата — имена в нижнем регистре.
+	LookupUsernames(usernames []string) (map[string]int64, error)
+}
+
+type MentionService struct {
+	repo      *repository.MentionRepository
+	postRepo  *repository.PostRepository
+	directory UserDirectory
+}
+
+func NewMentionService(repo *repository.MentionRepository, postRepo *repository.PostRepository, directory UserDirectory) *MentionService {
+	return &MentionService{repo: repo, postRepo: postRepo, directory: directory}
+}
+
+// Sync сохраняет упоминания из текста поста или, если commentID не равен 0,
+// комментария. Упоминания автором самого себя не сохраняются. Имена, еще
+// не известные каталогу, сопоставляются с пользователями при их входе.
+// Sync вызывается после сохранения текста, поэтому ошибки только
+// записываются в журнал: ответ с ошибкой привел бы к повторной отправке
+// уже сохраненного поста. Упоминания восстановятся при следующей правке.
+func (s *MentionService) Sync(postID, commentID, authorID int64, content string) {
+	if err := s.sync(postID, commentID, authorID, content); err != nil {
+		log.Printf("Ошибка сохранения упоминаний поста %d, комментария %d: %s\n", postID, commentID, err.Error())
+	}
+}
+
+func (s *MentionService) sync(postID, commentID, authorID int64, content string) error {
+	usernames := parseMentions(content)
+
+	var userIDs map[string]int64
+	if len(usernames) > 0 {
+		var err error
+		if userIDs, err = s.directory.LookupUsernames(usernames); err != nil {
+			return err
+		}
+	}
+
+	mentions := make([]*model.Mention, 0, len(usernames))
+	for _, username := range usernames {
+		mention := &model.Mention{Username: username}
+		if userID, ok := userIDs[strings.ToLower(username)]; ok {
+			if userID == authorID {
+				continue
+			}
+			mention.UserID = &userID
+		}
+		mentions = append(mentions, mention)
+	}
+
+	return s.repo.Replace(postID, commentID, authorID, mentions)
+}
+
+// GetMine возвращает страницу упоминаний пользователя вместе с постами
+func (s *MentionService) GetMine(userID int64, req pagination.Request) (pagination.Page[*model.Mention], error) {
+	details := map[string]string{}
+	validatePageRequest(req, details)
+	if err := newValidationError(details); err != nil {
+		return pagination.Page[*model.Mention]{}, err
+	}
+
+	page, err := s.repo.GetByUser(userID, req)
+	if err != nil {
+		return pagination.Page[*model.Mention]{}, err
+	}
+
+	ids := make([]int64, len(page.Items))
+	for i, mention := range page.Items {
+		ids[i] = mention.PostID
+	}
+	posts, err := s.postRepo.GetByIDs(ids)
+	if err != nil {
+		return pagination.Page[*model.Mention]{}, err
+	}
+
+	byID := make(map[int64]*model.Post, len(posts))
+	for _, post := range posts {
+		byID[post.ID] = post
+	}
+	for _, mention := range page.Items {
+		mention.Post = byID[mention.PostID]
+	}
+	return page, nil
+}
+
+// parseMentions возвращает имена, упомянутые в тексте вне блоков кода, без
+// повторов с точностью до регистра
+func parseMentions(content string) []string {
+	content = codePattern.ReplaceAllString(content, " ")
+
+	seen := make(map[string]bool)
+	usernames := []string{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		// Точка или дефис в конце относятся к тексту, а не к имени
+		username := strings.TrimRight(match[1], ".-")
+		key := strings.ToLower(username)
+		if len([]rune(username)) < 3 || seen[key] {
+			continue
+		}
+		seen[key] = true
+		usernames = append(usernames, username)
+		if len(usernames) == maxMentions {
+			break
+		}
+	}
+	return usernames
+}
@@ -0,0 +1,17 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/fire9900/golang-forum/internal/pagination"
+)
+
+// validatePageRequest добавляет в details ошибки параметров страницы
+func validatePageRequest(req pagination.Request, details map[string]string) {
+	if req.Limit < 1 || req.Limit > pagination.MaxLimit {
+		details["per_page"] = fmt.Sprintf("must be between 1 and %d", pagination.MaxLimit)
+	}
+	if req.After != "" && req.Before != "" {
+		details["before"] = "after and before cannot be used together"
+	}
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/repository"
+)
+
+const (
+	maxPollQuestionLength = 300
+	maxPollOptionLength   = 200
+	minPollOptions        = 2
+	maxPollOptions        = 10
+)
+
+type PollService struct {
+	repo     *repository.PollRepository
+	postRepo *repository.PostRepository
+}
+
+func NewPollService(repo *repository.PollRepository, postRepo *repository.PostRepository) *PollService {
+	return &PollService{repo: repo, postRepo: postRepo}
+}
+
+// Create прикрепляет опрос к посту. Создать опрос может автор поста
+// или модератор.
+func (s *PollService) Create(postID, userID int64, asModerator bool, req model.PollRequest) (*model.Poll, error) {
+	poll, err := preparePoll(postID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.ownedPost(postID, userID, asModerator); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.Create(poll)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPostNotFound
+		}
+		return nil, err
+	}
+	if !created {
+		return nil, ErrPollExists
+	}
+	return s.Get(postID, userID)
+}
+
+// Get возвращает опрос поста. Неопубликованный пост доступен только автору.
+// Для анонимного пользователя viewerID равен 0.
+func (s *PollService) Get(postID, viewerID int64) (*model.Poll, error) {
+	post, err := s.postRepo.GetByIDAnyStatus(postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPostNotFound
+		}
+		return nil, err
+	}
+	if post.Status != model.PostStatusPublished && post.AuthorID != viewerID {
+		return nil, ErrPostNotFound
+	}
+
+	poll, err := s.repo.GetByPostID(postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPollNotFound
+		}
+		return nil, err
+	}
+
+	if viewerID != 0 {
+		if poll.MyVotes, err = s.repo.GetUserVotes(poll.ID, viewerID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Результаты скрытого опроса доступны только после его закрытия
+	poll.ResultsVisible = !poll.HideResults || poll.Closed
+	if !poll.ResultsVisible {
+		poll.Voters = nil
+		for _, option := range poll.Options {
+			option.Votes = nil
+		}
+		return poll, nil
+	}
+
+	if !poll.Anonymous {
+		voters, err := s.repo.GetVoters(poll.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, option := range poll.Options {
+			option.VoterIDs = voters[option.ID]
+		}
+	}
+	return poll, nil
+}
+
+// Results возвращает опрос с результатами голосования. Результаты скрытого
+// опроса недоступны до его закрытия.
+func (s *PollService) Results(postID, viewerID int64) (*model.Poll, error) {
+	poll, err := s.Get(postID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if !poll.ResultsVisible {
+		return nil, ErrPollResultsHidden
+	}
+	return poll, nil
+}
+
+// Vote сохраняет голос пользователя. Каждый пользователь голосует один раз.
+func (s *PollService) Vote(postID, userID int64, optionIDs []int64) (*model.Poll, error) {
+	if _, err := s.postRepo.GetByID(postID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPostNotFound
+		}
+		return nil, err
+	}
+
+	poll, err := s.repo.GetByPostID(postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPollNotFound
+		}
+		return nil, err
+	}
+	if poll.Closed {
+		return nil, ErrPollClosed
+	}
+
+	if err := validatePollVote(poll, optionIDs); err != nil {
+		return nil, err
+	}
+
+	voted, err := s.repo.Vote(poll.ID, userID, optionIDs)
+	if err != nil {
+		return nil, err
+	}
+	if !voted {
+		return nil, ErrAlreadyVoted
+	}
+	return s.Get(postID, userID)
+}
+
+// Close досрочно закрывает опрос. Закрыть опрос может автор поста
+// или модератор.
+func (s *PollService) Close(postID, userID int64, asModerator bool) (*model.Poll, error) {
+	if _, err := s.ownedPost(postID, userID, asModerator); err != nil {
+		return nil, err
+	}
+
+	poll, err := s.repo.GetByPostID(postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPollNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.repo.Close(poll.ID); err != nil {
+		return nil, err
+	}
+	return s.Get(postID, userID)
+}
+
+// ownedPost возвращает пост, если пользователь может управлять его опросом
+func (s *PollService) ownedPost(postID, userID int64, asModerator bool) (*model.Post, error) {
+	post, err := s.postRepo.GetByIDAnyStatus(postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPostNotFound
+		}
+		return nil, err
+	}
+	if post.AuthorID != userID && !asModerator {
+		return nil, ErrPollForbidden
+	}
+	return post, nil
+}
+
+// preparePoll проверяет запрос и строит по нему опрос
+func preparePoll(postID int64, req model.PollRequest) (*model.Poll, error) {
+	details := map[string]string{}
+
+	poll := &model.Poll{
+		PostID:      postID,
+		Question:    strings.TrimSpace(req.Question),
+		Multiple:    req.Multiple,
+		Anonymous:   req.Anonymous,
+		HideResults: req.HideResults,
+		ClosesAt:    req.ClosesAt,
+	}
+	if poll.Question == "" {
+		details["question"] = "must not be empty"
+	} else if utf8.RuneCountInString(poll.Question) > maxPollQuestionLength {
+		details["question"] = fmt.Sprintf("must be at most %d characters", maxPollQuestionLength)
+	}
+
+	seen := make(map[string]bool, len(req.Options))
+	for _, text := range req.Options {
+		text = strings.TrimSpace(text)
+		switch {
+		case text == "":
+			details["options"] = "must not contain empty options"
+		case utf8.RuneCountInString(text) > maxPollOptionLength:
+			details["options"] = fmt.Sprintf("each option must be at most %d characters", maxPollOptionLength)
+		case seen[text]:
+			details["options"] = "must not contain duplicates"
+		}
+		seen[text] = true
+		poll.Options = append(poll.Options, &model.PollOption{Text: text})
+	}
+	if _, ok := details["options"]; !ok && (len(poll.Options) < minPollOptions || len(poll.Options) > maxPollOptions) {
+		details["options"] = fmt.Sprintf("must contain between %d and %d options", minPollOptions, maxPollOptions)
+	}
+
+	if poll.ClosesAt != nil && !poll.ClosesAt.After(time.Now()) {
+		details["closes_at"] = "must be in the future"
+	}
+
+	if err := newValidationError(details); err != nil {
+		return nil, err
+	}
+	return poll, nil
+}
+
+// validatePollVote проверяет, что выбранные варианты принадлежат опросу
+// и их число соответствует типу опроса
+func validatePollVote(poll *model.Poll, optionIDs []int64) error {
+	valid := make(map[int64]bool, len(poll.Options))
+	for _, option := range poll.Options {
+		valid[option.ID] = true
+	}
+
+	seen := make(map[int64]bool, len(optionIDs))
+	for _, id := range optionIDs {
+		if !valid[id] || seen[id] {
+			return newValidationError(map[string]string{"option_ids": "must contain distinct options of this poll"})
+		}
+		seen[id] = true
+	}
+
+	switch {
+	case len(optionIDs) == 0:
+		return newValidationError(map[string]string{"option_ids": "must not be empty"})
+	case !poll.Multiple && len(optionIDs) > 1:
+		return newValidationError(map[string]string{"option_ids": "must contain exactly one option"})
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/fire9900/golang-forum/internal/repository"
+)
+
+// purgeBatchSize ограничивает число постов, удаляемых в одной транзакции
+const purgeBatchSize = 100
+
+// PostPurger периодически окончательно удаляет посты, пролежавшие в корзине
+// дольше срока хранения
+type PostPurger struct {
+	repo      *repository.PostRepository
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewPostPurger(repo *repository.PostRepository, retention, interval time.Duration) *PostPurger {
+	return &PostPurger{repo: repo, retention: retention, interval: interval}
+}
+
+// Run выполняет очистку корзины до отмены ctx
+func (p *PostPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *PostPurger) purge() {
+	total := 0
+	for {
+		n, err := p.repo.Purge(p.retention, purgeBatchSize)
+		if err != nil {
+			log.Printf("Ошибка очистки корзины: %s\n", err.Error())
+			return
+		}
+		total += n
+		if n < purgeBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Printf("Из корзины окончательно удалено постов: %d\n", total)
+	}
+}
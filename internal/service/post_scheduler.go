@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/fire9900/golang-forum/internal/repository"
+)
+
+// publishBatchSize ограничивает число постов, публикуемых в одной транзакции
+const publishBatchSize = 100
+
+// PostScheduler периодически публикует отложенные посты, время публикации
+// которых наступило. Может одновременно работать в нескольких экземплярах
+// приложения.
+type PostScheduler struct {
+	repo     *repository.PostRepository
+	interval time.Duration
+}
+
+func NewPostScheduler(repo *repository.PostRepository, interval time.Duration) *PostScheduler {
+	return &PostScheduler{repo: repo, interval: interval}
+}
+
+// Run публикует готовые посты до отмены ctx
+func (s *PostScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.publishDue()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *PostScheduler) publishDue() {
+	for {
+		ids, err := s.repo.PublishDue(publishBatchSize)
+		if err != nil {
+			log.Printf("Ошибка публикации отложенных постов: %s\n", err.Error())
+			return
+		}
+		if len(ids) > 0 {
+			log.Printf("Опубликованы отложенные посты: %v\n", ids)
+		}
+		if len(ids) < publishBatchSize {
+			return
+		}
+	}
+}
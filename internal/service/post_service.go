@@ -1,35 +1,283 @@
 package service
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fire9900/golang-forum/internal/markdown"
 	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/pagination"
 	"github.com/fire9900/golang-forum/internal/repository"
+	"github.com/fire9900/golang-forum/internal/slug"
 )
 
+// maxTitleFilterLength ограничивает длину подстроки для поиска по заголовку
+const maxTitleFilterLength = 255
+
 type PostService struct {
-	repo *repository.PostRepository
+	repo         *repository.PostRepository
+	categoryRepo *repository.CategoryRepository
+	mentions     *MentionService
 }
 
-func NewPostService(repo *repository.PostRepository) *PostService {
-	return &PostService{repo: repo}
+func NewPostService(repo *repository.PostRepository, categoryRepo *repository.CategoryRepository, mentions *MentionService) *PostService {
+	return &PostService{repo: repo, categoryRepo: categoryRepo, mentions: mentions}
 }
 
 func (s *PostService) Create(post *model.Post) error {
-	return s.repo.Create(post)
+	if err := s.prepare(post); err != nil {
+		return err
+	}
+	if err := s.repo.Create(post); err != nil {
+		return err
+	}
+	s.mentions.Sync(post.ID, 0, post.AuthorID, post.Content)
+	return s.reload(post)
 }
 
 func (s *PostService) GetByID(id int64) (*model.Post, error) {
 	return s.repo.GetByID(id)
 }
 
-func (s *PostService) GetAll(page, perPage int) ([]*model.Post, error) {
-	offset := (page - 1) * perPage
-	return s.repo.GetAll(perPage, offset)
+// GetBySlug возвращает пост по текущему или прежнему slug. Если slug
+// устарел, у возвращенного поста он отличается от запрошенного.
+func (s *PostService) GetBySlug(slug string) (*model.Post, error) {
+	post, err := s.repo.GetBySlug(slug)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPostNotFound
+		}
+		return nil, err
+	}
+	return post, nil
+}
+
+func (s *PostService) GetAll(filter model.PostFilter, req pagination.Request) (pagination.Page[*model.Post], error) {
+	if err := s.prepareFilter(&filter, req); err != nil {
+		return pagination.Page[*model.Post]{}, err
+	}
+
+	return s.repo.GetAll(filter, req)
+}
+
+// GetByCategory возвращает посты раздела, указанного в filter.CategoryID
+func (s *PostService) GetByCategory(filter model.PostFilter, req pagination.Request) (pagination.Page[*model.Post], error) {
+	if _, err := s.categoryRepo.GetByID(filter.CategoryID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return pagination.Page[*model.Post]{}, ErrCategoryNotFound
+		}
+		return pagination.Page[*model.Post]{}, err
+	}
+
+	return s.GetAll(filter, req)
 }
 
 func (s *PostService) Update(post *model.Post) error {
-	return s.repo.Update(post)
+	current, err := s.repo.GetByIDAnyStatus(post.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPostNotFound
+		}
+		return err
+	}
+	if current.Locked {
+		return ErrPostLocked
+	}
+
+	// Без явного статуса правка не меняет состояние публикации
+	if post.Status == "" {
+		post.Status = current.Status
+		post.PublishAt = current.PublishAt
+	}
+
+	if err := s.prepare(post); err != nil {
+		return err
+	}
+	if err := s.repo.Update(post); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPostNotFound
+		}
+		return err
+	}
+	s.mentions.Sync(post.ID, 0, post.AuthorID, post.Content)
+	return s.reload(post)
+}
+
+// Delete переносит пост в корзину
+func (s *PostService) Delete(id, userID int64, asModerator bool) error {
+	if err := s.repo.Delete(id, userID, asModerator); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPostNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Restore возвращает пост из корзины. Восстановить пост может модератор
+// или автор, если пост удалил он сам, а не модератор.
+func (s *PostService) Restore(id, userID int64, asModerator bool) (*model.Post, error) {
+	if err := s.repo.Restore(id, userID, asModerator); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPostNotFound
+		}
+		return nil, err
+	}
+	return s.repo.GetByIDAnyStatus(id)
+}
+
+// Pin закрепляет пост глобально или только в его разделе
+func (s *PostService) Pin(id int64, scope string) (*model.Post, error) {
+	if scope == "" {
+		scope = model.PinScopeGlobal
+	}
+	if scope != model.PinScopeGlobal && scope != model.PinScopeCategory {
+		return nil, newValidationError(map[string]string{"scope": "must be one of: global, category"})
+	}
+	return s.updateFlags(id, func() error { return s.repo.SetPinned(id, scope) })
+}
+
+func (s *PostService) Unpin(id int64) (*model.Post, error) {
+	return s.updateFlags(id, func() error { return s.repo.SetPinned(id, "") })
+}
+
+// SetLocked закрывает или открывает тему для комментариев и правок
+func (s *PostService) SetLocked(id int64, locked bool) (*model.Post, error) {
+	return s.updateFlags(id, func() error { return s.repo.SetLocked(id, locked) })
+}
+
+// SetAnnouncement делает пост объявлением, показываемым во всех разделах,
+// или снимает этот статус
+func (s *PostService) SetAnnouncement(id int64, announcement bool) (*model.Post, error) {
+	return s.updateFlags(id, func() error { return s.repo.SetAnnouncement(id, announcement) })
+}
+
+func (s *PostService) updateFlags(id int64, update func() error) (*model.Post, error) {
+	if err := update(); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPostNotFound
+		}
+		return nil, err
+	}
+	return s.repo.GetByIDAnyStatus(id)
+}
+
+// GetDrafts возвращает черновики и отложенные посты пользователя
+func (s *PostService) GetDrafts(authorID int64) ([]*model.Post, error) {
+	return s.repo.GetDraftsByAuthor(authorID)
+}
+
+// GetTrash возвращает удаленные посты пользователя, которые еще можно восстановить
+func (s *PostService) GetTrash(authorID int64) ([]*model.Post, error) {
+	return s.repo.GetDeletedByAuthor(authorID)
+}
+
+// reload перечитывает пост после записи, чтобы вернуть клиенту
+// значения, заполняемые базой данных
+func (s *PostService) reload(post *model.Post) error {
+	saved, err := s.repo.GetByIDAnyStatus(post.ID)
+	if err != nil {
+		return err
+	}
+	*post = *saved
+	return nil
+}
+
+// prepare проверяет раздел и статус публикации поста, нормализует его теги,
+// строит slug из заголовка и HTML из текста
+func (s *PostService) prepare(post *model.Post) error {
+	details := make(map[string]string)
+
+	post.Slug = slug.Make(post.Title)
+	html, err := markdown.Render(post.Content)
+	if err != nil {
+		return err
+	}
+	post.ContentHTML = html
+
+	tags, err := normalizeTags(post.Tags)
+	if err != nil {
+		details["tags"] = err.Error()
+	}
+	post.Tags = tags
+
+	switch post.Status {
+	case "":
+		post.Status = model.PostStatusPublished
+		post.PublishAt = nil
+	case model.PostStatusDraft, model.PostStatusPublished:
+		post.PublishAt = nil
+	case model.PostStatusScheduled:
+		if post.PublishAt == nil || !post.PublishAt.After(time.Now()) {
+			details["publish_at"] = "scheduled posts require a publish_at in the future"
+		}
+	default:
+		details["status"] = "must be one of: draft, scheduled, published"
+	}
+
+	if err := newValidationError(details); err != nil {
+		return err
+	}
+	return s.ensureCategory(post.CategoryID)
+}
+
+// prepareFilter проверяет и нормализует условия выборки постов
+func (s *PostService) prepareFilter(filter *model.PostFilter, req pagination.Request) error {
+	details := make(map[string]string)
+	validatePageRequest(req, details)
+
+	tags, err := normalizeTags(filter.Tags)
+	if err != nil {
+		details["tag"] = err.Error()
+	}
+	filter.Tags = tags
+
+	switch filter.TagMode {
+	case "":
+		filter.TagMode = model.TagModeOr
+	case model.TagModeAnd, model.TagModeOr:
+	default:
+		details["tag_mode"] = "must be one of: and, or"
+	}
+
+	switch filter.Sort {
+	case "":
+		filter.Sort = model.PostSortNewest
+	case model.PostSortNewest, model.PostSortOldest, model.PostSortUpdated,
+		model.PostSortComments, model.PostSortActive,
+		model.PostSortTop, model.PostSortHot, model.PostSortControversial, model.PostSortViews:
+	default:
+		details["sort"] = "must be one of: newest, oldest, updated, comments, active, top, hot, controversial, views"
+	}
+
+	if filter.AuthorID < 0 {
+		details["author_id"] = "must be a positive integer"
+	}
+	filter.Title = strings.TrimSpace(filter.Title)
+	if utf8.RuneCountInString(filter.Title) > maxTitleFilterLength {
+		details["title"] = fmt.Sprintf("must be at most %d characters", maxTitleFilterLength)
+	}
+	if filter.CreatedAfter != nil && filter.CreatedBefore != nil && !filter.CreatedAfter.Before(*filter.CreatedBefore) {
+		details["created_before"] = "must be later than created_after"
+	}
+	if filter.UpdatedAfter != nil && filter.UpdatedBefore != nil && !filter.UpdatedAfter.Before(*filter.UpdatedBefore) {
+		details["updated_before"] = "must be later than updated_after"
+	}
+
+	return newValidationError(details)
 }
 
-func (s *PostService) Delete(id, authorID int64) error {
-	return s.repo.Delete(id, authorID)
+// ensureCategory проверяет, что раздел, в который публикуется пост, существует
+func (s *PostService) ensureCategory(categoryID int64) error {
+	if _, err := s.categoryRepo.GetByID(categoryID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidCategory
+		}
+		return err
+	}
+	return nil
 }
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/pagination"
+	"github.com/fire9900/golang-forum/internal/repository"
+)
+
+// ReactionService управляет реакциями на посты и комментарии. Во всех
+// методах commentID, равный 0, означает реакцию на сам пост.
+type ReactionService struct {
+	repo        *repository.ReactionRepository
+	postRepo    *repository.PostRepository
+	commentRepo *repository.CommentRepository
+	allowed     []string
+}
+
+func NewReactionService(
+	repo *repository.ReactionRepository,
+	postRepo *repository.PostRepository,
+	commentRepo *repository.CommentRepository,
+	allowed []string,
+) *ReactionService {
+	return &ReactionService{repo: repo, postRepo: postRepo, commentRepo: commentRepo, allowed: allowed}
+}
+
+// Allowed возвращает набор разрешенных эмодзи
+func (s *ReactionService) Allowed() []string {
+	return s.allowed
+}
+
+// React добавляет реакцию пользователя и возвращает обновленную сводку
+func (s *ReactionService) React(postID, commentID, userID int64, emoji string) (*model.ReactionSummary, error) {
+	if err := s.validateEmoji(emoji); err != nil {
+		return nil, err
+	}
+
+	targetType, targetID, err := s.target(postID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Add(targetType, targetID, userID, emoji); err != nil {
+		return nil, err
+	}
+	return s.summary(targetType, targetID, userID)
+}
+
+// Unreact удаляет реакцию пользователя и возвращает обновленную сводку
+func (s *ReactionService) Unreact(postID, commentID, userID int64, emoji string) (*model.ReactionSummary, error) {
+	targetType, targetID, err := s.target(postID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Remove(targetType, targetID, userID, emoji); err != nil {
+		return nil, err
+	}
+	return s.summary(targetType, targetID, userID)
+}
+
+// Summary возвращает сводку реакций. Для анонимного пользователя userID
+// равен 0.
+func (s *ReactionService) Summary(postID, commentID, userID int64) (*model.ReactionSummary, error) {
+	targetType, targetID, err := s.target(postID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.summary(targetType, targetID, userID)
+}
+
+// Reactors возвращает страницу пользователей, оставивших реакцию emoji
+func (s *ReactionService) Reactors(postID, commentID int64, emoji string, req pagination.Request) (pagination.Page[*model.Reactor], error) {
+	details := map[string]string{}
+	validatePageRequest(req, details)
+	if err := newValidationError(details); err != nil {
+		return pagination.Page[*model.Reactor]{}, err
+	}
+
+	targetType, targetID, err := s.target(postID, commentID)
+	if err != nil {
+		return pagination.Page[*model.Reactor]{}, err
+	}
+	return s.repo.GetReactors(targetType, targetID, emoji, req)
+}
+
+// target проверяет существование объекта реакции и возвращает его тип
+// и идентификатор
+func (s *ReactionService) target(postID, commentID int64) (string, int64, error) {
+	if _, err := s.postRepo.GetByID(postID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", 0, ErrPostNotFound
+		}
+		return "", 0, err
+	}
+
+	if commentID == 0 {
+		return model.ReactionTargetPost, postID, nil
+	}
+
+	comment, err := s.commentRepo.GetByID(commentID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", 0, err
+	}
+	if comment == nil || comment.PostID != postID {
+		return "", 0, ErrCommentNotFound
+	}
+	return model.ReactionTargetComment, commentID, nil
+}
+
+// summary загружает сводку и упорядочивает эмодзи так же, как в конфигурации
+func (s *ReactionService) summary(targetType string, targetID, userID int64) (*model.ReactionSummary, error) {
+	summary, err := s.repo.Summary(targetType, targetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rank := func(emoji string) int {
+		if i := slices.Index(s.allowed, emoji); i >= 0 {
+			return i
+		}
+		return len(s.allowed)
+	}
+	slices.SortFunc(summary.Counts, func(a, b model.ReactionCount) int {
+		if d := rank(a.Emoji) - rank(b.Emoji); d != 0 {
+			return d
+		}
+		return strings.Compare(a.Emoji, b.Emoji)
+	})
+	slices.SortFunc(summary.Mine, func(a, b string) int {
+		if d := rank(a) - rank(b); d != 0 {
+			return d
+		}
+		return strings.Compare(a, b)
+	})
+	return summary, nil
+}
+
+func (s *ReactionService) validateEmoji(emoji string) error {
+	if slices.Contains(s.allowed, emoji) {
+		return nil
+	}
+	return newValidationError(map[string]string{
+		"emoji": "must be one of: " + strings.Join(s.allowed, " "),
+	})
+}
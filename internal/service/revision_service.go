@@ -0,0 +1,133 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/fire9900/golang-forum/internal/diff"
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/repository"
+)
+
+// diffContextLines — число строк контекста вокруг изменений в unified diff
+const diffContextLines = 3
+
+type RevisionService struct {
+	repo     *repository.RevisionRepository
+	postRepo *repository.PostRepository
+}
+
+func NewRevisionService(repo *repository.RevisionRepository, postRepo *repository.PostRepository) *RevisionService {
+	return &RevisionService{repo: repo, postRepo: postRepo}
+}
+
+// GetHistory возвращает историю правок поста с перечнем измененных полей
+func (s *RevisionService) GetHistory(postID int64) ([]*model.RevisionSummary, error) {
+	if err := s.ensurePublished(postID); err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.repo.GetByPostID(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]*model.RevisionSummary, 0, len(revisions))
+	for i, revision := range revisions {
+		summary := &model.RevisionSummary{
+			Revision:  revision.Revision,
+			EditorID:  revision.EditorID,
+			CreatedAt: revision.CreatedAt,
+			Changed:   []string{},
+		}
+		if i > 0 {
+			prev := revisions[i-1]
+			if prev.Title != revision.Title {
+				summary.Changed = append(summary.Changed, "title")
+			}
+			if prev.Content != revision.Content {
+				summary.Changed = append(summary.Changed, "content")
+			}
+		}
+		history = append(history, summary)
+	}
+	return history, nil
+}
+
+// Get возвращает ревизию опубликованного поста
+func (s *RevisionService) Get(postID int64, number int) (*model.PostRevision, error) {
+	if err := s.ensurePublished(postID); err != nil {
+		return nil, err
+	}
+	return s.get(postID, number)
+}
+
+func (s *RevisionService) get(postID int64, number int) (*model.PostRevision, error) {
+	revision, err := s.repo.GetByNumber(postID, number)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, err
+	}
+	return revision, nil
+}
+
+// Diff сравнивает две ревизии поста построчно (unified) или по словам (words)
+func (s *RevisionService) Diff(postID int64, from, to int, mode string) (*model.RevisionDiff, error) {
+	if mode == "" {
+		mode = model.DiffModeUnified
+	}
+	if mode != model.DiffModeUnified && mode != model.DiffModeWords {
+		return nil, newValidationError(map[string]string{"mode": "must be one of: unified, words"})
+	}
+
+	if err := s.ensurePublished(postID); err != nil {
+		return nil, err
+	}
+
+	fromRevision, err := s.get(postID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := s.get(postID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.RevisionDiff{
+		PostID: postID,
+		From:   from,
+		To:     to,
+		Mode:   mode,
+		Title:  diff.Words(fromRevision.Title, toRevision.Title),
+	}
+
+	switch mode {
+	case model.DiffModeUnified:
+		result.Unified = diff.Unified(
+			fromRevision.Content,
+			toRevision.Content,
+			fmt.Sprintf("revision %d", from),
+			fmt.Sprintf("revision %d", to),
+			diffContextLines,
+		)
+	case model.DiffModeWords:
+		result.Content = diff.Words(fromRevision.Content, toRevision.Content)
+	}
+
+	return result, nil
+}
+
+// ensurePublished проверяет, что пост опубликован и не удален: история
+// черновиков, запланированных и удаленных постов не раскрывается
+func (s *RevisionService) ensurePublished(postID int64) error {
+	if _, err := s.postRepo.GetByID(postID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPostNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fire9900/golang-forum/internal/model"
+)
+
+const (
+	minSearchQueryLength = 2
+	maxSearchQueryLength = 200
+	defaultSearchPerPage = 20
+	maxSearchPerPage     = 50
+
+	// snippetLength — длина фрагмента текста в символах
+	snippetLength = 200
+)
+
+// SearchIndex описывает поисковый индекс по постам и комментариям
+type SearchIndex interface {
+	// Search возвращает найденные объекты, упорядоченные по релевантности,
+	// и общее количество совпадений
+	Search(query string, limit, offset int) ([]*model.SearchHit, int, error)
+}
+
+type SearchService struct {
+	index SearchIndex
+}
+
+func NewSearchService(index SearchIndex) *SearchService {
+	return &SearchService{index: index}
+}
+
+func (s *SearchService) Search(query string, page, perPage int) (*model.SearchResults, error) {
+	query = strings.TrimSpace(query)
+	if n := utf8.RuneCountInString(query); n < minSearchQueryLength || n > maxSearchQueryLength {
+		return nil, newValidationError(map[string]string{
+			"q": fmt.Sprintf("must be between %d and %d characters", minSearchQueryLength, maxSearchQueryLength),
+		})
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = defaultSearchPerPage
+	}
+	if perPage > maxSearchPerPage {
+		perPage = maxSearchPerPage
+	}
+
+	hits, total, err := s.index.Search(query, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := searchTerms(query)
+	for _, hit := range hits {
+		hit.Snippet = highlight(hit.Content, terms, snippetLength)
+	}
+
+	return &model.SearchResults{
+		Query:   query,
+		Hits:    hits,
+		Total:   total,
+		Page:    page,
+		PerPage: perPage,
+	}, nil
+}
+
+// searchTerms разбивает поисковый запрос на слова в нижнем регистре
+func searchTerms(query string) [][]rune {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([][]rune, 0, len(words))
+	for _, word := range words {
+		if utf8.RuneCountInString(word) >= minSearchQueryLength {
+			terms = append(terms, []rune(word))
+		}
+	}
+	return terms
+}
+
+// highlight вырезает из текста фрагмент вокруг первого совпадения и выделяет
+// найденные слова тегом <mark>. Остальной текст экранируется.
+func highlight(text string, terms [][]rune, length int) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// marked[i] содержит длину совпадения, начинающегося с позиции i
+	marked := make(map[int]int)
+	first := -1
+	for i := 0; i < len(lower); i++ {
+		for _, term := range terms {
+			if hasPrefixAt(lower, term, i) {
+				marked[i] = len(term)
+				if first < 0 {
+					first = i
+				}
+				i += len(term) - 1
+				break
+			}
+		}
+	}
+
+	start := 0
+	if first > length/4 {
+		start = first - length/4
+	}
+	end := min(start+length, len(runes))
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; i++ {
+		if n, ok := marked[i]; ok {
+			n = min(n, end-i)
+			b.WriteString("<mark>")
+			b.WriteString(html.EscapeString(string(runes[i : i+n])))
+			b.WriteString("</mark>")
+			i += n - 1
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[i])))
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+func hasPrefixAt(text, term []rune, pos int) bool {
+	if pos+len(term) > len(text) {
+		return false
+	}
+	for j, r := range term {
+		if text[pos+j] != r {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/pagination"
+	"github.com/fire9900/golang-forum/internal/repository"
+)
+
+type SubscriptionService struct {
+	repo     *repository.SubscriptionRepository
+	postRepo *repository.PostRepository
+}
+
+func NewSubscriptionService(repo *repository.SubscriptionRepository, postRepo *repository.PostRepository) *SubscriptionService {
+	return &SubscriptionService{repo: repo, postRepo: postRepo}
+}
+
+// Set подписывает пользователя на опубликованную тему или меняет уровень
+// существующей подписки
+func (s *SubscriptionService) Set(userID, postID int64, level string) (*model.Subscription, error) {
+	if err := validateSubscriptionLevel(level, "level"); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.postRepo.GetByID(postID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPostNotFound
+		}
+		return nil, err
+	}
+
+	return s.repo.Set(userID, postID, level)
+}
+
+// Get возвращает подписку пользователя на тему
+func (s *SubscriptionService) Get(userID, postID int64) (*model.Subscription, error) {
+	subscription, err := s.repo.Get(userID, postID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSubscriptionNotFound
+	}
+	return subscription, err
+}
+
+// Delete отменяет подписку пользователя на тему
+func (s *SubscriptionService) Delete(userID, postID int64) error {
+	if err := s.repo.Delete(userID, postID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSubscriptionNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// GetByUser возвращает страницу подписок пользователя вместе с темами.
+// Если level не пуст, выбираются только подписки этого уровня.
+func (s *SubscriptionService) GetByUser(userID int64, level string, req pagination.Request) (pagination.Page[*model.Subscription], error) {
+	details := map[string]string{}
+	validatePageRequest(req, details)
+	if err := newValidationError(details); err != nil {
+		return pagination.Page[*model.Subscription]{}, err
+	}
+	if level != "" {
+		if err := validateSubscriptionLevel(level, "level"); err != nil {
+			return pagination.Page[*model.Subscription]{}, err
+		}
+	}
+
+	page, err := s.repo.GetByUser(userID, level, req)
+	if err != nil {
+		return pagination.Page[*model.Subscription]{}, err
+	}
+
+	ids := make([]int64, len(page.Items))
+	for i, subscription := range page.Items {
+		ids[i] = subscription.PostID
+	}
+	posts, err := s.postRepo.GetByIDs(ids)
+	if err != nil {
+		return pagination.Page[*model.Subscription]{}, err
+	}
+
+	byID := make(map[int64]*model.Post, len(posts))
+	for _, post := range posts {
+		byID[post.ID] = post
+	}
+	for _, subscription := range page.Items {
+		subscription.Post = byID[subscription.PostID]
+	}
+	return page, nil
+}
+
+func validateSubscriptionLevel(level, field string) error {
+	switch level {
+	case model.SubscriptionWatching, model.SubscriptionTracking, model.SubscriptionMuted:
+		return nil
+	default:
+		return newValidationError(map[string]string{field: "must be one of: watching, tracking, muted"})
+	}
+}
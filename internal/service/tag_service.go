@@ -0,0 +1,56 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/repository"
+)
+
+const (
+	maxTagsPerPost = 10
+	maxTagLength   = 50
+)
+
+type TagService struct {
+	repo *repository.TagRepository
+}
+
+func NewTagService(repo *repository.TagRepository) *TagService {
+	return &TagService{repo: repo}
+}
+
+func (s *TagService) GetAll() ([]*model.Tag, error) {
+	return s.repo.GetAll()
+}
+
+// normalizeTags приводит теги к нижнему регистру, заменяет пробелы на дефисы
+// и убирает дубликаты, сохраняя порядок
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.Join(strings.Fields(strings.ToLower(tag)), "-")
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, fmt.Errorf("tag %q is longer than %d characters", tag, maxTagLength)
+		}
+		for _, r := range tag {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_.+#", r) {
+				return nil, fmt.Errorf("tag %q contains invalid character %q", tag, r)
+			}
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > maxTagsPerPost {
+		return nil, fmt.Errorf("a post can have at most %d tags", maxTagsPerPost)
+	}
+	return normalized, nil
+}
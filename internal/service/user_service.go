@@ -0,0 +1,167 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/repository"
+)
+
+type UserService struct {
+	repo *repository.UserRepository
+}
+
+func NewUserService(repo *repository.UserRepository) *UserService {
+	return &UserService{repo: repo}
+}
+
+// Sync сохраняет данные пользователя, полученные от сервиса авторизации
+func (s *UserService) Sync(user *model.User) error {
+	return s.repo.Upsert(user)
+}
+
+// GetProfile возвращает публичный профиль пользователя
+func (s *UserService) GetProfile(id int64) (*model.User, error) {
+	return s.profile(s.repo.GetProfile(id))
+}
+
+// GetProfileByUsername возвращает публичный профиль пользователя по имени
+func (s *UserService) GetProfileByUsername(username string) (*model.User, error) {
+	return s.profile(s.repo.GetProfileByUsername(username))
+}
+
+func (s *UserService) profile(user *model.User, err error) (*model.User, error) {
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	user.Avatar = avatarURL(user.ID)
+	return user, nil
+}
+
+// AttachPostAuthors заполняет авторов постов
+func (s *UserService) AttachPostAuthors(posts []*model.Post) error {
+	ids := make([]int64, len(posts))
+	for i, post := range posts {
+		ids[i] = post.AuthorID
+	}
+
+	authors, err := s.authors(ids)
+	if err != nil {
+		return err
+	}
+	for _, post := range posts {
+		post.Author = authors[post.AuthorID]
+	}
+	return nil
+}
+
+// AttachCommentAuthors заполняет авторов комментариев
+func (s *UserService) AttachCommentAuthors(comments []*model.Comment) error {
+	ids := make([]int64, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.AuthorID
+	}
+
+	authors, err := s.authors(ids)
+	if err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		comment.Author = authors[comment.AuthorID]
+	}
+	return nil
+}
+
+// AttachBookmarkAuthors заполняет авторов постов из закладок
+func (s *UserService) AttachBookmarkAuthors(bookmarks []*model.Bookmark) error {
+	posts := make([]*model.Post, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		if bookmark.Post != nil {
+			posts = append(posts, bookmark.Post)
+		}
+	}
+	return s.AttachPostAuthors(posts)
+}
+
+// AttachSubscriptionAuthors заполняет авторов постов из подписок
+func (s *UserService) AttachSubscriptionAuthors(subscriptions []*model.Subscription) error {
+	posts := make([]*model.Post, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		if subscription.Post != nil {
+			posts = append(posts, subscription.Post)
+		}
+	}
+	return s.AttachPostAuthors(posts)
+}
+
+// AttachMentionAuthors заполняет авторов упоминаний и постов, в которых
+// они сделаны
+func (s *UserService) AttachMentionAuthors(mentions []*model.Mention) error {
+	ids := make([]int64, 0, 2*len(mentions))
+	for _, mention := range mentions {
+		ids = append(ids, mention.AuthorID)
+		if mention.Post != nil {
+			ids = append(ids, mention.Post.AuthorID)
+		}
+	}
+
+	authors, err := s.authors(ids)
+	if err != nil {
+		return err
+	}
+	for _, mention := range mentions {
+		mention.Author = authors[mention.AuthorID]
+		if mention.Post != nil {
+			mention.Post.Author = authors[mention.Post.AuthorID]
+		}
+	}
+	return nil
+}
+
+// AttachSearchAuthors заполняет авторов найденных постов и комментариев
+func (s *UserService) AttachSearchAuthors(hits []*model.SearchHit) error {
+	ids := make([]int64, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.AuthorID
+	}
+
+	authors, err := s.authors(ids)
+	if err != nil {
+		return err
+	}
+	for _, hit := range hits {
+		hit.Author = authors[hit.AuthorID]
+	}
+	return nil
+}
+
+// authors загружает авторов с идентификаторами ids. Пользователи, еще не
+// входившие на форум после появления каталога, возвращаются без имени и
+// аватара.
+func (s *UserService) authors(ids []int64) (map[int64]*model.Author, error) {
+	unique := make([]int64, 0, len(ids))
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	authors, err := s.repo.GetAuthors(unique)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range unique {
+		if authors[id] == nil {
+			authors[id] = &model.Author{ID: id}
+			continue
+		}
+		authors[id].Avatar = avatarURL(id)
+	}
+	return authors, nil
+}
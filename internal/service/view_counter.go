@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/fire9900/golang-forum/internal/repository"
+)
+
+const (
+	// viewFlushBatchSize ограничивает число постов в одном запросе записи
+	viewFlushBatchSize = 500
+	// maxTrackedViews ограничивает число запоминаемых просмотров. При
+	// переполнении забываются самые старые, и такой посетитель может быть
+	// учтен повторно раньше окончания окна.
+	maxTrackedViews = 100000
+)
+
+type viewKey struct {
+	postID int64
+	viewer string
+}
+
+type viewEntry struct {
+	key viewKey
+	at  time.Time
+}
+
+// ViewCounter накапливает просмотры постов в памяти и периодически
+// записывает их в базу. Повторный просмотр поста тем же посетителем в
+// течение окна дедупликации не учитывается. Просмотры, не записанные
+// из-за ошибки, добавляются к следующей записи.
+type ViewCounter struct {
+	repo     *repository.PostRepository
+	interval time.Duration
+	window   time.Duration
+
+	mu   sync.Mutex
+	seen map[viewKey]time.Time
+	// order хранит просмотры из seen в порядке учета, чтобы забывать
+	// истекшие и самые старые без обхода всей seen
+	order   []viewEntry
+	pending map[int64]int
+}
+
+func NewViewCounter(repo *repository.PostRepository, interval, window time.Duration) *ViewCounter {
+	return &ViewCounter{
+		repo:     repo,
+		interval: interval,
+		window:   window,
+		seen:     make(map[viewKey]time.Time),
+		pending:  make(map[int64]int),
+	}
+}
+
+// Record учитывает просмотр поста посетителем viewer
+func (v *ViewCounter) Record(postID int64, viewer string) {
+	key := viewKey{postID: postID, viewer: viewer}
+	now := time.Now()
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.forget(now.Add(-v.window))
+	if at, ok := v.seen[key]; ok && now.Sub(at) < v.window {
+		return
+	}
+	if len(v.order) >= maxTrackedViews {
+		v.forgetOldest()
+	}
+	v.seen[key] = now
+	v.order = append(v.order, viewEntry{key: key, at: now})
+	v.pending[postID]++
+}
+
+// forget забывает просмотры, учтенные раньше cutoff
+func (v *ViewCounter) forget(cutoff time.Time) {
+	for len(v.order) > 0 && v.order[0].at.Before(cutoff) {
+		v.forgetOldest()
+	}
+}
+
+func (v *ViewCounter) forgetOldest() {
+	entry := v.order[0]
+	v.order = v.order[1:]
+	// Ключ мог быть учтен заново после истечения окна
+	if v.seen[entry.key].Equal(entry.at) {
+		delete(v.seen, entry.key)
+	}
+}
+
+// Run записывает просмотры до отмены ctx, после чего записывает оставшиеся
+func (v *ViewCounter) Run(ctx context.Context) {
+	ticker := time.NewTicker(v.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			v.Flush()
+			return
+		case <-ticker.C:
+			v.Flush()
+		}
+	}
+}
+
+// Flush записывает накопленные просмотры в базу и забывает посетителей,
+// окно дедупликации которых истекло
+func (v *ViewCounter) Flush() {
+	v.mu.Lock()
+	pending := v.pending
+	v.pending = make(map[int64]int)
+	v.forget(time.Now().Add(-v.window))
+	v.mu.Unlock()
+
+	batch := make(map[int64]int, min(len(pending), viewFlushBatchSize))
+	for postID, count := range pending {
+		batch[postID] = count
+		if len(batch) == viewFlushBatchSize {
+			v.write(batch)
+			batch = make(map[int64]int, viewFlushBatchSize)
+		}
+	}
+	v.write(batch)
+}
+
+func (v *ViewCounter) write(views map[int64]int) {
+	if err := v.repo.AddViews(views); err != nil {
+		log.Printf("Ошибка записи просмотров постов: %s\n", err.Error())
+
+		v.mu.Lock()
+		for postID, count := range views {
+			v.pending[postID] += count
+		}
+		v.mu.Unlock()
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/fire9900/golang-forum/internal/model"
+	"github.com/fire9900/golang-forum/internal/repository"
+)
+
+type VoteService struct {
+	repo        *repository.VoteRepository
+	postRepo    *repository.PostRepository
+	commentRepo *repository.CommentRepository
+}
+
+func NewVoteService(
+	repo *repository.VoteRepository,
+	postRepo *repository.PostRepository,
+	commentRepo *repository.CommentRepository,
+) *VoteService {
+	return &VoteService{repo: repo, postRepo: postRepo, commentRepo: commentRepo}
+}
+
+// VotePost голосует за опубликованный пост. Значение 0 отзывает голос.
+func (s *VoteService) VotePost(postID, userID int64, value int) (*model.VoteResult, error) {
+	if err := s.ensurePost(postID); err != nil {
+		return nil, err
+	}
+
+	result, err := s.repo.VotePost(postID, userID, value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPostNotFound
+	}
+	return result, err
+}
+
+// VoteComment голосует за комментарий к опубликованному посту
+func (s *VoteService) VoteComment(postID, commentID, userID int64, value int) (*model.VoteResult, error) {
+	if err := s.ensurePost(postID); err != nil {
+		return nil, err
+	}
+
+	comment, err := s.commentRepo.GetByID(commentID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if comment == nil || comment.PostID != postID {
+		return nil, ErrCommentNotFound
+	}
+
+	result, err := s.repo.VoteComment(commentID, userID, value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCommentNotFound
+	}
+	return result, err
+}
+
+func (s *VoteService) ensurePost(postID int64) error {
+	if _, err := s.postRepo.GetByID(postID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPostNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Package slug строит из заголовков человекочитаемые идентификаторы для
+// адресов страниц. Кириллица транслитерируется латиницей.
+package slug
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MaxLength ограничивает длину slug в байтах
+const MaxLength = 80
+
+// fallback используется, если в заголовке нет ни букв, ни цифр
+const fallback = "post"
+
+// translit сопоставляет буквам русского и украинского алфавитов их
+// латинскую запись
+var translit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g",
+}
+
+// Make возвращает slug заголовка: латинские буквы в нижнем регистре и
+// цифры, разделенные одиночными дефисами
+func Make(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		part, ok := translit[r]
+		if !ok && r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			part, ok = string(r), true
+		}
+		if !ok {
+			// Остальные символы, в том числе буквы других алфавитов,
+			// разделяют слова
+			dash = b.Len() > 0
+			continue
+		}
+		if part == "" {
+			continue
+		}
+		if dash {
+			b.WriteByte('-')
+			dash = false
+		}
+		b.WriteString(part)
+	}
+
+	s := b.String()
+	if len(s) > MaxLength {
+		s = s[:MaxLength]
+		if i := strings.LastIndexByte(s, '-'); i > MaxLength/2 {
+			s = s[:i]
+		}
+		s = strings.TrimRight(s, "-")
+	}
+	if s == "" {
+		return fallback
+	}
+	return s
+}
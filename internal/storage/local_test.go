@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const key, content = "attachments/2024/01/abc.txt", "hello"
+	if err := store.Put(ctx, key, strings.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	r, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != content {
+		t.Errorf("Get = %q, want %q", data, content)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete: err = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("Delete of missing object: %v", err)
+	}
+}
+
+func TestLocalStoreShortWrite(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Put(context.Background(), "a.txt", strings.NewReader("abc"), 10, ""); err == nil {
+		t.Fatal("Put with wrong size succeeded")
+	}
+	if _, err := store.Get(context.Background(), "a.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("partial object is visible: err = %v", err)
+	}
+}
+
+func TestLocalStoreRejectsKeysOutsideDir(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	dir := filepath.Join(root, "store")
+	store, err := NewLocalStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Файл рядом с каталогом хранилища, до которого не должен добраться ни один ключ
+	outside := filepath.Join(root, "x")
+	if err := os.WriteFile(outside, []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := []string{
+		"../x",
+		"a/../../x",
+		"./../x",
+		"/x",
+		`..\x`,
+		`a\..\..\x`,
+		"",
+		"..",
+	}
+	for _, key := range keys {
+		if err := store.Put(ctx, key, strings.NewReader("evil"), 4, ""); err == nil {
+			t.Errorf("Put(%q) succeeded", key)
+		}
+		if r, err := store.Get(ctx, key); err == nil {
+			r.Close()
+			t.Errorf("Get(%q) succeeded", key)
+		}
+		if err := store.Delete(ctx, key); err == nil {
+			t.Errorf("Delete(%q) succeeded", key)
+		}
+	}
+
+	data, err := os.ReadFile(outside)
+	if err != nil {
+		t.Fatalf("file outside storage was removed: %v", err)
+	}
+	if string(data) != "secret" {
+		t.Errorf("file outside storage was overwritten: %q", data)
+	}
+}
@@ -0,0 +1,45 @@
+// Package storage хранит содержимое загруженных файлов. Метаданные файлов
+// хранятся в MySQL, а само содержимое — в BlobStore.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/fire9900/golang-forum/internal/config"
+)
+
+// ErrNotFound возвращается, если объекта с указанным ключом нет в хранилище
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore хранит объекты по ключам. Ключи состоят из латинских букв,
+// цифр, точек, дефисов и косых черт.
+type BlobStore interface {
+	// Put сохраняет size байт из r под ключом key, заменяя существующий объект
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get открывает объект для чтения. Вызывающий должен закрыть результат.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete удаляет объект. Удаление отсутствующего объекта не является ошибкой.
+	Delete(ctx context.Context, key string) error
+}
+
+// New создает хранилище, выбранное в конфигурации
+func New(cfg config.StorageConfig) (BlobStore, error) {
+	switch cfg.Backend {
+	case "local":
+		return NewLocalStore(cfg.LocalDir)
+	case "s3":
+		return NewS3Store(S3Options{
+			Endpoint:  cfg.S3.Endpoint,
+			Region:    cfg.S3.Region,
+			Bucket:    cfg.S3.Bucket,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			PathStyle: cfg.S3.PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
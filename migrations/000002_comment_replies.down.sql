@@ -0,0 +1,7 @@
+START TRANSACTION;
+
+ALTER TABLE comments
+    DROP INDEX idx_comments_post_parent,
+    DROP COLUMN parent_id;
+
+COMMIT;
@@ -0,0 +1,7 @@
+START TRANSACTION;
+
+ALTER TABLE comments
+    ADD COLUMN parent_id INT NULL AFTER post_id,
+    ADD INDEX idx_comments_post_parent (post_id, parent_id, created_at, id);
+
+COMMIT;
@@ -0,0 +1,9 @@
+START TRANSACTION;
+
+ALTER TABLE posts
+    DROP INDEX idx_posts_category,
+    DROP COLUMN category_id;
+
+DROP TABLE IF EXISTS categories;
+
+COMMIT;
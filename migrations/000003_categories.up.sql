@@ -0,0 +1,23 @@
+START TRANSACTION;
+
+CREATE TABLE IF NOT EXISTS categories (
+    id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    parent_id INT NULL,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(500) NOT NULL DEFAULT '',
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_categories_parent (parent_id, position)
+);
+
+-- Все существующие посты попадают в раздел по умолчанию
+INSERT INTO categories (id, name, description) VALUES (1, 'Общее', 'Общие обсуждения');
+
+ALTER TABLE posts
+    ADD COLUMN category_id INT NOT NULL DEFAULT 1 AFTER content,
+    ADD INDEX idx_posts_category (category_id, created_at, id);
+
+ALTER TABLE posts ALTER COLUMN category_id DROP DEFAULT;
+
+COMMIT;
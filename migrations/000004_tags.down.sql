@@ -0,0 +1,6 @@
+START TRANSACTION;
+
+DROP TABLE IF EXISTS post_tags;
+DROP TABLE IF EXISTS tags;
+
+COMMIT;
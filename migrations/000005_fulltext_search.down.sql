@@ -0,0 +1,7 @@
+START TRANSACTION;
+
+ALTER TABLE comments DROP INDEX ft_comments_content;
+ALTER TABLE posts DROP INDEX ft_posts_title_content;
+ALTER TABLE posts DROP INDEX ft_posts_title;
+
+COMMIT;
@@ -0,0 +1,8 @@
+START TRANSACTION;
+
+-- InnoDB добавляет FULLTEXT-индексы только по одному за операцию
+ALTER TABLE posts ADD FULLTEXT INDEX ft_posts_title (title);
+ALTER TABLE posts ADD FULLTEXT INDEX ft_posts_title_content (title, content);
+ALTER TABLE comments ADD FULLTEXT INDEX ft_comments_content (content);
+
+COMMIT;
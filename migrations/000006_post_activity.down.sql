@@ -0,0 +1,12 @@
+START TRANSACTION;
+
+ALTER TABLE posts
+    DROP INDEX idx_posts_author,
+    DROP INDEX idx_posts_last_activity,
+    DROP INDEX idx_posts_comment_count,
+    DROP INDEX idx_posts_updated,
+    DROP INDEX idx_posts_created,
+    DROP COLUMN last_activity_at,
+    DROP COLUMN comment_count;
+
+COMMIT;
@@ -0,0 +1,25 @@
+START TRANSACTION;
+
+ALTER TABLE posts
+    ADD COLUMN comment_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN last_activity_at TIMESTAMP NULL DEFAULT NULL;
+
+UPDATE posts p
+LEFT JOIN (
+    SELECT post_id, COUNT(*) AS cnt, MAX(created_at) AS last_comment_at
+    FROM comments
+    GROUP BY post_id
+) c ON c.post_id = p.id
+SET p.comment_count = COALESCE(c.cnt, 0),
+    p.last_activity_at = GREATEST(p.created_at, COALESCE(c.last_comment_at, p.created_at)),
+    p.updated_at = p.updated_at;
+
+ALTER TABLE posts
+    MODIFY COLUMN last_activity_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD INDEX idx_posts_created (created_at, id),
+    ADD INDEX idx_posts_updated (updated_at, id),
+    ADD INDEX idx_posts_comment_count (comment_count, id),
+    ADD INDEX idx_posts_last_activity (last_activity_at, id),
+    ADD INDEX idx_posts_author (author_id, created_at, id);
+
+COMMIT;
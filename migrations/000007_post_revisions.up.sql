@@ -0,0 +1,18 @@
+START TRANSACTION;
+
+CREATE TABLE IF NOT EXISTS post_revisions (
+    id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    post_id INT NOT NULL,
+    revision INT NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    content TEXT NOT NULL,
+    editor_id INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_post_revisions_post (post_id, revision)
+);
+
+-- Текущее состояние существующих постов становится их первой ревизией
+INSERT INTO post_revisions (post_id, revision, title, content, editor_id, created_at)
+SELECT id, 1, title, content, author_id, updated_at FROM posts;
+
+COMMIT;
@@ -0,0 +1,8 @@
+START TRANSACTION;
+
+ALTER TABLE posts
+    DROP INDEX idx_posts_deleted,
+    DROP COLUMN deleted_by,
+    DROP COLUMN deleted_at;
+
+COMMIT;
@@ -0,0 +1,8 @@
+START TRANSACTION;
+
+ALTER TABLE posts
+    ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL,
+    ADD COLUMN deleted_by INT NULL,
+    ADD INDEX idx_posts_deleted (deleted_at);
+
+COMMIT;
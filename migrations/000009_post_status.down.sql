@@ -0,0 +1,8 @@
+START TRANSACTION;
+
+ALTER TABLE posts
+    DROP INDEX idx_posts_status_publish,
+    DROP COLUMN publish_at,
+    DROP COLUMN status;
+
+COMMIT;
@@ -0,0 +1,8 @@
+START TRANSACTION;
+
+ALTER TABLE posts
+    ADD COLUMN status ENUM('draft', 'scheduled', 'published') NOT NULL DEFAULT 'published',
+    ADD COLUMN publish_at TIMESTAMP NULL DEFAULT NULL,
+    ADD INDEX idx_posts_status_publish (status, publish_at);
+
+COMMIT;
@@ -0,0 +1,10 @@
+START TRANSACTION;
+
+ALTER TABLE posts
+    DROP INDEX idx_posts_announcement,
+    DROP COLUMN is_announcement,
+    DROP COLUMN is_locked,
+    DROP COLUMN pin_scope,
+    DROP COLUMN is_pinned;
+
+COMMIT;
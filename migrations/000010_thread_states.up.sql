@@ -0,0 +1,10 @@
+START TRANSACTION;
+
+ALTER TABLE posts
+    ADD COLUMN is_pinned BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN pin_scope ENUM('global', 'category') NULL DEFAULT NULL,
+    ADD COLUMN is_locked BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN is_announcement BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD INDEX idx_posts_announcement (is_announcement);
+
+COMMIT;
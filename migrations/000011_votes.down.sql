@@ -0,0 +1,21 @@
+START TRANSACTION;
+
+ALTER TABLE comments
+    DROP COLUMN score,
+    DROP COLUMN downvotes,
+    DROP COLUMN upvotes;
+
+ALTER TABLE posts
+    DROP INDEX idx_posts_controversy,
+    DROP INDEX idx_posts_hot,
+    DROP INDEX idx_posts_score,
+    DROP COLUMN controversy,
+    DROP COLUMN hot_score,
+    DROP COLUMN score,
+    DROP COLUMN downvotes,
+    DROP COLUMN upvotes;
+
+DROP TABLE IF EXISTS comment_votes;
+DROP TABLE IF EXISTS post_votes;
+
+COMMIT;
@@ -0,0 +1,36 @@
+START TRANSACTION;
+
+CREATE TABLE IF NOT EXISTS post_votes (
+    post_id INT NOT NULL,
+    user_id INT NOT NULL,
+    value TINYINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (post_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS comment_votes (
+    comment_id INT NOT NULL,
+    user_id INT NOT NULL,
+    value TINYINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (comment_id, user_id)
+);
+
+ALTER TABLE posts
+    ADD COLUMN upvotes INT NOT NULL DEFAULT 0,
+    ADD COLUMN downvotes INT NOT NULL DEFAULT 0,
+    ADD COLUMN score INT NOT NULL DEFAULT 0,
+    ADD COLUMN hot_score DOUBLE NOT NULL DEFAULT 0,
+    ADD COLUMN controversy DOUBLE NOT NULL DEFAULT 0,
+    ADD INDEX idx_posts_score (score, id),
+    ADD INDEX idx_posts_hot (hot_score, id),
+    ADD INDEX idx_posts_controversy (controversy, id);
+
+ALTER TABLE comments
+    ADD COLUMN upvotes INT NOT NULL DEFAULT 0,
+    ADD COLUMN downvotes INT NOT NULL DEFAULT 0,
+    ADD COLUMN score INT NOT NULL DEFAULT 0;
+
+COMMIT;
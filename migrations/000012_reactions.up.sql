@@ -0,0 +1,13 @@
+START TRANSACTION;
+
+CREATE TABLE IF NOT EXISTS reactions (
+    target_type ENUM('post', 'comment') NOT NULL,
+    target_id INT NOT NULL,
+    user_id INT NOT NULL,
+    emoji VARCHAR(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (target_type, target_id, user_id, emoji),
+    INDEX idx_reactions_emoji (target_type, target_id, emoji, created_at, user_id)
+);
+
+COMMIT;
@@ -0,0 +1,16 @@
+START TRANSACTION;
+
+CREATE TABLE IF NOT EXISTS bookmarks (
+    user_id INT NOT NULL,
+    post_id INT NOT NULL,
+    folder VARCHAR(100) NOT NULL DEFAULT '',
+    note TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, post_id),
+    INDEX idx_bookmarks_user_created (user_id, created_at, post_id),
+    INDEX idx_bookmarks_user_folder (user_id, folder, created_at, post_id),
+    INDEX idx_bookmarks_post (post_id)
+);
+
+COMMIT;
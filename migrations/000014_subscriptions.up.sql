@@ -0,0 +1,14 @@
+START TRANSACTION;
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+    user_id INT NOT NULL,
+    post_id INT NOT NULL,
+    level ENUM('watching', 'tracking', 'muted') NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, post_id),
+    INDEX idx_subscriptions_user_created (user_id, created_at, post_id),
+    INDEX idx_subscriptions_post_level (post_id, level)
+);
+
+COMMIT;
@@ -0,0 +1,34 @@
+START TRANSACTION;
+
+CREATE TABLE IF NOT EXISTS polls (
+    id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    post_id INT NOT NULL,
+    question VARCHAR(300) NOT NULL,
+    is_multiple BOOLEAN NOT NULL DEFAULT FALSE,
+    is_anonymous BOOLEAN NOT NULL DEFAULT FALSE,
+    hide_results BOOLEAN NOT NULL DEFAULT FALSE,
+    voters INT NOT NULL DEFAULT 0,
+    closes_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_polls_post (post_id)
+);
+
+CREATE TABLE IF NOT EXISTS poll_options (
+    id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    poll_id INT NOT NULL,
+    position INT NOT NULL,
+    text VARCHAR(200) NOT NULL,
+    votes INT NOT NULL DEFAULT 0,
+    INDEX idx_poll_options_poll (poll_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS poll_votes (
+    poll_id INT NOT NULL,
+    option_id INT NOT NULL,
+    user_id INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (poll_id, user_id, option_id),
+    INDEX idx_poll_votes_option (option_id, created_at)
+);
+
+COMMIT;
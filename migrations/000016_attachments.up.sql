@@ -0,0 +1,19 @@
+START TRANSACTION;
+
+CREATE TABLE IF NOT EXISTS attachments (
+    id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    post_id INT NULL,
+    comment_id INT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_attachments_storage_key (storage_key),
+    INDEX idx_attachments_user (user_id),
+    INDEX idx_attachments_post (post_id, comment_id),
+    INDEX idx_attachments_comment (comment_id)
+);
+
+COMMIT;
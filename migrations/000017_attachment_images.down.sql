@@ -0,0 +1,12 @@
+START TRANSACTION;
+
+DROP TABLE IF EXISTS attachment_variants;
+
+ALTER TABLE attachments
+    DROP INDEX idx_attachments_processing,
+    DROP COLUMN height,
+    DROP COLUMN width,
+    DROP COLUMN processing_started_at,
+    DROP COLUMN processing;
+
+COMMIT;
@@ -0,0 +1,21 @@
+START TRANSACTION;
+
+ALTER TABLE attachments
+    ADD COLUMN processing ENUM('none', 'pending', 'processing', 'done', 'failed') NOT NULL DEFAULT 'none',
+    ADD COLUMN processing_started_at TIMESTAMP NULL DEFAULT NULL,
+    ADD COLUMN width INT NULL DEFAULT NULL,
+    ADD COLUMN height INT NULL DEFAULT NULL,
+    ADD INDEX idx_attachments_processing (processing, id);
+
+CREATE TABLE IF NOT EXISTS attachment_variants (
+    attachment_id INT NOT NULL,
+    name VARCHAR(20) NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    PRIMARY KEY (attachment_id, name)
+);
+
+COMMIT;
@@ -0,0 +1,9 @@
+START TRANSACTION;
+
+DROP TABLE IF EXISTS post_slugs;
+
+ALTER TABLE posts
+    DROP INDEX uq_posts_slug,
+    DROP COLUMN slug;
+
+COMMIT;
@@ -0,0 +1,23 @@
+START TRANSACTION;
+
+ALTER TABLE posts ADD COLUMN slug VARCHAR(80) NULL AFTER title;
+
+-- Существующие посты получают временный slug; заголовочный slug
+-- появится при следующей правке заголовка
+UPDATE posts SET slug = CONCAT('post-', id), updated_at = updated_at;
+
+ALTER TABLE posts
+    MODIFY COLUMN slug VARCHAR(80) NOT NULL,
+    ADD UNIQUE KEY uq_posts_slug (slug);
+
+-- История slug: все адреса, когда-либо принадлежавшие посту, включая текущий
+CREATE TABLE IF NOT EXISTS post_slugs (
+    slug VARCHAR(80) NOT NULL PRIMARY KEY,
+    post_id INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_post_slugs_post (post_id)
+);
+
+INSERT INTO post_slugs (slug, post_id) SELECT slug, id FROM posts;
+
+COMMIT;
@@ -0,0 +1,7 @@
+START TRANSACTION;
+
+ALTER TABLE posts
+    DROP INDEX idx_posts_views,
+    DROP COLUMN view_count;
+
+COMMIT;
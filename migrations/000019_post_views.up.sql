@@ -0,0 +1,7 @@
+START TRANSACTION;
+
+ALTER TABLE posts
+    ADD COLUMN view_count INT NOT NULL DEFAULT 0,
+    ADD INDEX idx_posts_views (view_count, id);
+
+COMMIT;
@@ -0,0 +1,6 @@
+START TRANSACTION;
+
+ALTER TABLE comments DROP COLUMN content_html;
+ALTER TABLE posts DROP COLUMN content_html;
+
+COMMIT;
@@ -0,0 +1,8 @@
+START TRANSACTION;
+
+-- Отрисованный HTML заполняется при записи текста; для существующих
+-- записей его заполняет фоновая задача
+ALTER TABLE posts ADD COLUMN content_html MEDIUMTEXT NULL AFTER content;
+ALTER TABLE comments ADD COLUMN content_html MEDIUMTEXT NULL AFTER content;
+
+COMMIT;
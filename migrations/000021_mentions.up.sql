@@ -0,0 +1,19 @@
+START TRANSACTION;
+
+-- Упоминания пользователей в постах (comment_id = 0) и комментариях.
+-- user_id остается NULL, пока имя не удалось сопоставить с пользователем.
+CREATE TABLE IF NOT EXISTS mentions (
+    id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    post_id INT NOT NULL,
+    comment_id INT NOT NULL DEFAULT 0,
+    author_id INT NOT NULL,
+    username VARCHAR(50) NOT NULL,
+    user_id INT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_mentions_target (post_id, comment_id, username),
+    INDEX idx_mentions_user (user_id, created_at, id),
+    INDEX idx_mentions_comment (comment_id),
+    INDEX idx_mentions_username (username)
+);
+
+COMMIT;
@@ -0,0 +1,14 @@
+START TRANSACTION;
+
+-- Копия пользователей сервиса авторизации; идентификаторы совпадают с
+-- идентификаторами в нем
+CREATE TABLE IF NOT EXISTS users (
+    id INT NOT NULL PRIMARY KEY,
+    username VARCHAR(50) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_users_username (username)
+);
+
+COMMIT;
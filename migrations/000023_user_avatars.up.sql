@@ -0,0 +1,14 @@
+START TRANSACTION;
+
+-- Копии загруженного аватара пользователя по размерам стороны в пикселях
+CREATE TABLE IF NOT EXISTS user_avatars (
+    user_id INT NOT NULL,
+    side INT NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, side)
+);
+
+COMMIT;
@@ -0,0 +1,11 @@
+START TRANSACTION;
+
+-- Строки для блокировки операций одного пользователя, которые затрагивают
+-- несколько его строк в других таблицах: например, проверки квоты вложений.
+-- Строка создается при первой блокировке, поэтому пользователь может еще
+-- отсутствовать в таблице users.
+CREATE TABLE IF NOT EXISTS user_locks (
+    user_id INT NOT NULL PRIMARY KEY
+);
+
+COMMIT;